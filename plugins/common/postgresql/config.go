@@ -71,6 +71,7 @@ func (c *Config) CreateService() (*Service, error) {
 		maxOpen:            c.MaxOpen,
 		maxLifetime:        time.Duration(c.MaxLifetime),
 		dsn:                stdlib.RegisterConnConfig(connConfig),
+		password:           connConfig.Password,
 	}, nil
 }
 
@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	// Blank import required to register driver
@@ -16,6 +17,7 @@ type Service struct {
 	ConnectionDatabase string
 
 	dsn         string
+	password    string
 	maxIdle     int
 	maxOpen     int
 	maxLifetime time.Duration
@@ -40,3 +42,35 @@ func (p *Service) Stop() {
 		p.DB.Close()
 	}
 }
+
+// SanitizeError removes sensitive information like passwords from the
+// message of the given error, e.g. if the error contains the connection
+// string. The original error is still accessible via unwrapping.
+func (p *Service) SanitizeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	sanitized := sanitizer.ReplaceAllString(msg, "")
+	if p.password != "" {
+		sanitized = strings.ReplaceAll(sanitized, p.password, "xxxxx")
+	}
+	if sanitized == msg {
+		return err
+	}
+	return &sanitizedError{msg: sanitized, err: err}
+}
+
+type sanitizedError struct {
+	msg string
+	err error
+}
+
+func (e *sanitizedError) Error() string {
+	return e.msg
+}
+
+func (e *sanitizedError) Unwrap() error {
+	return e.err
+}
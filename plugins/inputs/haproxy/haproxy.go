@@ -2,6 +2,7 @@
 package haproxy
 
 import (
+	"bufio"
 	_ "embed"
 	"encoding/csv"
 	"errors"
@@ -11,12 +12,16 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -45,19 +50,49 @@ var (
 // CSV format: https://cbonte.github.io/haproxy-dconv/1.5/configuration.html#9.1
 
 type HAProxy struct {
-	Servers        []string `toml:"servers"`
-	KeepFieldNames bool     `toml:"keep_field_names"`
-	Username       string   `toml:"username"`
-	Password       string   `toml:"password"`
+	Servers        []string        `toml:"servers"`
+	KeepFieldNames bool            `toml:"keep_field_names"`
+	Username       string          `toml:"username"`
+	Password       string          `toml:"password"`
+	Timeout        config.Duration `toml:"timeout"`
+	MaxParallel    int             `toml:"max_parallel"`
+	CollectInfo    bool            `toml:"collect_info"`
+
+	ServerNameRegex       string   `toml:"server_name_regex"`
+	ServerNameReplacement string   `toml:"server_name_replacement"`
+	ProxyInclude          []string `toml:"proxy_include"`
+	ProxyExclude          []string `toml:"proxy_exclude"`
 	tls.ClientConfig
 
-	client *http.Client
+	client          *http.Client
+	serverNameRegex *regexp.Regexp
+	proxyFilter     filter.Filter
 }
 
 func (*HAProxy) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *HAProxy) Init() error {
+	if h.ServerNameRegex != "" {
+		re, err := regexp.Compile(h.ServerNameRegex)
+		if err != nil {
+			return fmt.Errorf("compiling server_name_regex failed: %w", err)
+		}
+		h.serverNameRegex = re
+	}
+
+	if len(h.ProxyInclude) > 0 || len(h.ProxyExclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(h.ProxyInclude, h.ProxyExclude)
+		if err != nil {
+			return fmt.Errorf("compiling proxy filter failed: %w", err)
+		}
+		h.proxyFilter = f
+	}
+
+	return nil
+}
+
 func (h *HAProxy) Gather(acc telegraf.Accumulator) error {
 	if len(h.Servers) == 0 {
 		return h.gatherServer("http://127.0.0.1:1936/haproxy?stats", acc)
@@ -86,11 +121,21 @@ func (h *HAProxy) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	// Limit the number of concurrently scraped servers if requested
+	var sem chan struct{}
+	if h.MaxParallel > 0 {
+		sem = make(chan struct{}, h.MaxParallel)
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(len(endpoints))
 	for _, server := range endpoints {
 		go func(serv string) {
 			defer wg.Done()
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
 			if err := h.gatherServer(serv, acc); err != nil {
 				acc.AddError(err)
 			}
@@ -101,7 +146,9 @@ func (h *HAProxy) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (h *HAProxy) gatherServerSocket(addr string, acc telegraf.Accumulator) error {
+// dialSocket connects to the given socket or tcp address and applies the
+// timeout to the connection. HAProxy handles a single command per connection.
+func (h *HAProxy) dialSocket(addr string) (net.Conn, string, error) {
 	var network, address string
 	if strings.HasPrefix(addr, "tcp://") {
 		network = "tcp"
@@ -113,15 +160,86 @@ func (h *HAProxy) gatherServerSocket(addr string, acc telegraf.Accumulator) erro
 
 	c, err := net.Dial(network, address)
 	if err != nil {
-		return fmt.Errorf("could not connect to '%s://%s': %w", network, address, err)
+		return nil, address, fmt.Errorf("could not connect to '%s://%s': %w", network, address, err)
 	}
 
+	if h.Timeout > 0 {
+		if err := c.SetDeadline(time.Now().Add(time.Duration(h.Timeout))); err != nil {
+			c.Close()
+			return nil, address, fmt.Errorf("setting deadline for '%s://%s' failed: %w", network, address, err)
+		}
+	}
+
+	return c, address, nil
+}
+
+func (h *HAProxy) gatherServerSocket(addr string, acc telegraf.Accumulator) error {
+	c, address, err := h.dialSocket(addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
 	_, errw := c.Write([]byte("show stat\n"))
 	if errw != nil {
-		return fmt.Errorf("could not write to socket '%s://%s': %w", network, address, errw)
+		return fmt.Errorf("could not write to socket '%s': %w", addr, errw)
+	}
+
+	if err := h.importCsvResult(c, acc, address); err != nil {
+		return err
+	}
+
+	if h.CollectInfo {
+		return h.gatherInfoSocket(addr, acc)
+	}
+	return nil
+}
+
+// gatherInfoSocket collects the process-level metrics of the 'show info'
+// command into the 'haproxy_process' measurement
+func (h *HAProxy) gatherInfoSocket(addr string, acc telegraf.Accumulator) error {
+	c, address, err := h.dialSocket(addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	_, errw := c.Write([]byte("show info\n"))
+	if errw != nil {
+		return fmt.Errorf("could not write to socket '%s': %w", addr, errw)
 	}
 
-	return h.importCsvResult(c, acc, address)
+	now := time.Now()
+	fields := make(map[string]interface{})
+	scanner := bufio.NewScanner(c)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			continue
+		}
+		if !h.KeepFieldNames {
+			key = strings.ToLower(key)
+		}
+
+		if vi, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields[key] = vi
+		} else if vf, err := strconv.ParseFloat(value, 64); err == nil {
+			fields[key] = vf
+		} else {
+			fields[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading info from '%s' failed: %w", addr, err)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("no info received from '%s'", addr)
+	}
+
+	acc.AddFields("haproxy_process", fields, map[string]string{"server": address}, now)
+	return nil
 }
 
 func (h *HAProxy) gatherServer(addr string, acc telegraf.Accumulator) error {
@@ -140,7 +258,7 @@ func (h *HAProxy) gatherServer(addr string, acc telegraf.Accumulator) error {
 		}
 		client := &http.Client{
 			Transport: tr,
-			Timeout:   4 * time.Second,
+			Timeout:   time.Duration(h.Timeout),
 		}
 		h.client = client
 	}
@@ -208,6 +326,12 @@ func (h *HAProxy) importCsvResult(r io.Reader, acc telegraf.Accumulator, host st
 	}
 	headers[0] = headers[0][2:]
 
+	// Locate the proxy name for filtering
+	proxyColumn := -1
+	if h.proxyFilter != nil {
+		proxyColumn = slices.Index(headers, "pxname")
+	}
+
 	for {
 		row, err := csvr.Read()
 		if errors.Is(err, io.EOF) {
@@ -225,6 +349,9 @@ func (h *HAProxy) importCsvResult(r io.Reader, acc telegraf.Accumulator, host st
 		if len(row) != len(headers) {
 			return fmt.Errorf("number of columns does not match number of headers. headers=%d columns=%d", len(headers), len(row))
 		}
+		if proxyColumn >= 0 && !h.proxyFilter.Match(row[proxyColumn]) {
+			continue
+		}
 		for i, v := range row {
 			if v == "" {
 				continue
@@ -239,7 +366,12 @@ func (h *HAProxy) importCsvResult(r io.Reader, acc telegraf.Accumulator, host st
 			}
 
 			switch colName {
-			case "pxname", "svname":
+			case "pxname":
+				tags[fieldName] = v
+			case "svname":
+				if h.serverNameRegex != nil {
+					v = h.serverNameRegex.ReplaceAllString(v, h.ServerNameReplacement)
+				}
 				tags[fieldName] = v
 			case "type":
 				vi, err := strconv.ParseInt(v, 10, 64)
@@ -278,6 +410,8 @@ func (h *HAProxy) importCsvResult(r io.Reader, acc telegraf.Accumulator, host st
 
 func init() {
 	inputs.Add("haproxy", func() telegraf.Input {
-		return &HAProxy{}
+		return &HAProxy{
+			Timeout: config.Duration(4 * time.Second),
+		}
 	})
 }
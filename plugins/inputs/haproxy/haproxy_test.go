@@ -11,9 +11,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -34,8 +38,11 @@ func serverSocket(l net.Listener) {
 			}
 
 			data := buf[:n]
-			if string(data) == "show stat\n" {
+			switch string(data) {
+			case "show stat\n":
 				c.Write(csvOutputSample) //nolint:errcheck // we return anyway
+			case "show info\n":
+				c.Write(infoOutputSample) //nolint:errcheck // we return anyway
 			}
 		}(conn)
 	}
@@ -211,6 +218,54 @@ func TestHaproxyGeneratesMetricsUsingTcp(t *testing.T) {
 	require.NoError(t, r.Gather(&acc))
 }
 
+func TestHaproxyCollectInfo(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	go serverSocket(l)
+
+	r := &HAProxy{
+		Servers:     []string{"tcp://" + l.Addr().String()},
+		CollectInfo: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{
+		"server": l.Addr().String(),
+	}
+	fields := map[string]interface{}{
+		"name":         "HAProxy",
+		"version":      "2.4.22-0ubuntu0.22.04.2",
+		"release_date": "2023/02/14",
+		"nbthread":     int64(4),
+		"nbproc":       int64(1),
+		"process_num":  int64(1),
+		"pid":          int64(1234),
+		"uptime":       "0d 1h02m03s",
+		"uptime_sec":   int64(3723),
+		"memmax_mb":    int64(0),
+		"poolalloc_mb": int64(0),
+		"poolused_mb":  int64(0),
+		"poolfailed":   int64(0),
+		"ulimit-n":     int64(8034),
+		"maxsock":      int64(8034),
+		"maxconn":      int64(4000),
+		"hard_maxconn": int64(4000),
+		"currconns":    int64(12),
+		"cumconns":     int64(123456),
+		"cumreq":       int64(234567),
+		"connrate":     int64(5),
+		"idle_pct":     int64(98),
+		"node":         "lb1",
+	}
+	acc.AssertContainsTaggedFields(t, "haproxy_process", fields, tags)
+	require.True(t, acc.HasMeasurement("haproxy"))
+}
+
 // When not passing server config, we default to localhost
 // We just want to make sure we did request stat from localhost
 func TestHaproxyDefaultGetFromLocalhost(t *testing.T) {
@@ -274,6 +329,160 @@ func TestHaproxyKeepFieldNames(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "haproxy", fields, tags)
 }
 
+func TestHaproxyServerNameRegex(t *testing.T) {
+	input := "# pxname,svname,scur,type\nwww,host0-abc123,3,2\nwww,BACKEND,5,1\n"
+
+	r := &HAProxy{
+		ServerNameRegex:       `^(.+)-[0-9a-f]+$`,
+		ServerNameReplacement: "$1",
+	}
+	require.NoError(t, r.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.importCsvResult(strings.NewReader(input), &acc, "localhost"))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"haproxy",
+			map[string]string{"server": "localhost", "proxy": "www", "sv": "host0", "type": "server"},
+			map[string]interface{}{"scur": uint64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy",
+			map[string]string{"server": "localhost", "proxy": "www", "sv": "BACKEND", "type": "backend"},
+			map[string]interface{}{"scur": uint64(5)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestHaproxyProxyFilter(t *testing.T) {
+	input := "# pxname,svname,scur,type\nwww,host0,3,2\nwww,BACKEND,5,1\ngit,host1,1,2\nstats,FRONTEND,0,0\n"
+
+	r := &HAProxy{
+		ProxyInclude: []string{"w*", "git"},
+		ProxyExclude: []string{"git"},
+	}
+	require.NoError(t, r.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.importCsvResult(strings.NewReader(input), &acc, "localhost"))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"haproxy",
+			map[string]string{"server": "localhost", "proxy": "www", "sv": "host0", "type": "server"},
+			map[string]interface{}{"scur": uint64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy",
+			map[string]string{"server": "localhost", "proxy": "www", "sv": "BACKEND", "type": "backend"},
+			map[string]interface{}{"scur": uint64(5)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestHaproxyInvalidServerNameRegex(t *testing.T) {
+	r := &HAProxy{ServerNameRegex: `host(`}
+	require.ErrorContains(t, r.Init(), "compiling server_name_regex failed")
+}
+
+func TestHaproxyConcurrentScrape(t *testing.T) {
+	delay := 500 * time.Millisecond
+	handler := func(d time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(d)
+			if _, err := w.Write(csvOutputSample); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		}
+	}
+	slow1 := httptest.NewServer(handler(delay))
+	defer slow1.Close()
+	slow2 := httptest.NewServer(handler(delay))
+	defer slow2.Close()
+	fast := httptest.NewServer(handler(0))
+	defer fast.Close()
+
+	tests := []struct {
+		name        string
+		maxParallel int
+		sequential  bool
+	}{
+		{
+			name: "unlimited",
+		},
+		{
+			name:        "limited",
+			maxParallel: 2,
+		},
+		{
+			name:        "sequential",
+			maxParallel: 1,
+			sequential:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &HAProxy{
+				Servers:     []string{slow1.URL, fast.URL, slow2.URL},
+				Timeout:     config.Duration(5 * time.Second),
+				MaxParallel: tt.maxParallel,
+			}
+
+			var acc testutil.Accumulator
+			start := time.Now()
+			require.NoError(t, plugin.Gather(&acc))
+			elapsed := time.Since(start)
+			require.Empty(t, acc.Errors)
+
+			for _, ts := range []*httptest.Server{slow1, slow2, fast} {
+				tags := map[string]string{
+					"server": ts.Listener.Addr().String(),
+					"proxy":  "git",
+					"sv":     "www",
+					"type":   "server",
+				}
+				acc.AssertContainsTaggedFields(t, "haproxy", haproxyGetFieldValues(), tags)
+			}
+
+			if tt.sequential {
+				require.GreaterOrEqual(t, elapsed, 2*delay)
+			} else {
+				require.Less(t, elapsed, 2*delay)
+			}
+		})
+	}
+}
+
+func TestHaproxyTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(time.Second)
+		if _, err := w.Write(csvOutputSample); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &HAProxy{
+		Servers: []string{ts.URL},
+		Timeout: config.Duration(100 * time.Millisecond),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.False(t, acc.HasMeasurement("haproxy"))
+}
+
 func mustReadSampleOutput() []byte {
 	filePath := "testdata/sample_output.csv"
 	data, err := os.ReadFile(filePath)
@@ -340,3 +549,15 @@ func haproxyGetFieldValues() map[string]interface{} {
 
 // Can obtain from official haproxy demo: 'http://demo.haproxy.org/;csv'
 var csvOutputSample = mustReadSampleOutput()
+
+var infoOutputSample = mustReadSampleInfo()
+
+func mustReadSampleInfo() []byte {
+	filePath := "testdata/sample_info.txt"
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		panic(fmt.Errorf("could not read from file %s: %w", filePath, err))
+	}
+
+	return data
+}
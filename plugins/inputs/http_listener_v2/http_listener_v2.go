@@ -2,13 +2,17 @@
 package http_listener_v2
 
 import (
+	"bufio"
 	"compress/gzip"
+	"context"
 	"crypto/subtle"
 	"crypto/tls"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
@@ -16,6 +20,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -44,36 +49,59 @@ const (
 	body               = "body"
 	query              = "query"
 	pathTag            = "http_listener_v2_path"
+
+	// defaultDeadLetterMaxFiles is the default number of files kept in the
+	// dead-letter directory
+	defaultDeadLetterMaxFiles = 100
+	// defaultDeadLetterMaxSize is the default maximum number of bytes of the
+	// request body written to a dead-letter file
+	defaultDeadLetterMaxSize = 1024 * 1024
+
+	// snappyStreamIdentifier is the chunk starting payloads in the snappy
+	// framing format
+	snappyStreamIdentifier = "\xff\x06\x00\x00sNaPpY"
 )
 
 type HTTPListenerV2 struct {
-	ServiceAddress string            `toml:"service_address"`
-	SocketMode     string            `toml:"socket_mode"`
-	Path           string            `toml:"path" deprecated:"1.20.0;1.35.0;use 'paths' instead"`
-	Paths          []string          `toml:"paths"`
-	PathTag        bool              `toml:"path_tag"`
-	Methods        []string          `toml:"methods"`
-	HTTPHeaders    map[string]string `toml:"http_headers"`
-	DataSource     string            `toml:"data_source"`
-	ReadTimeout    config.Duration   `toml:"read_timeout"`
-	WriteTimeout   config.Duration   `toml:"write_timeout"`
-	MaxBodySize    config.Size       `toml:"max_body_size"`
-	Port           int               `toml:"port" deprecated:"1.32.0;1.35.0;use 'service_address' instead"`
-	SuccessCode    int               `toml:"http_success_code"`
-	BasicUsername  string            `toml:"basic_username"`
-	BasicPassword  string            `toml:"basic_password"`
-	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
+	ServiceAddress      string            `toml:"service_address"`
+	SocketMode          string            `toml:"socket_mode"`
+	Path                string            `toml:"path" deprecated:"1.20.0;1.35.0;use 'paths' instead"`
+	Paths               []string          `toml:"paths"`
+	PathTag             bool              `toml:"path_tag"`
+	Methods             []string          `toml:"methods"`
+	HTTPHeaders         map[string]string `toml:"http_headers"`
+	DataSource          string            `toml:"data_source"`
+	ReadTimeout         config.Duration   `toml:"read_timeout"`
+	WriteTimeout        config.Duration   `toml:"write_timeout"`
+	MaxBodySize         config.Size       `toml:"max_body_size"`
+	Port                int               `toml:"port" deprecated:"1.32.0;1.35.0;use 'service_address' instead"`
+	SuccessCode         int               `toml:"http_success_code"`
+	SuccessBody         string            `toml:"http_success_body"`
+	BasicUsername       string            `toml:"basic_username"`
+	BasicPassword       string            `toml:"basic_password"`
+	HTTPHeaderTags      map[string]string `toml:"http_header_tags"`
+	SourceTag           string            `toml:"source_tag"`
+	RequiredContentType string            `toml:"required_content_type"`
+
+	ShutdownTimeout config.Duration `toml:"shutdown_timeout"`
+
+	DeadLetterDir      string      `toml:"dead_letter_dir"`
+	DeadLetterMaxFiles int         `toml:"dead_letter_max_files"`
+	DeadLetterMaxSize  config.Size `toml:"dead_letter_max_size"`
 
 	common_tls.ServerConfig
 	tlsConf *tls.Config
 
 	timeFunc
-	Log telegraf.Logger
+	Log telegraf.Logger `toml:"-"`
 
 	wg    sync.WaitGroup
 	close chan struct{}
 
+	deadLetterMu sync.Mutex
+
 	listener net.Listener
+	server   *http.Server
 	url      *url.URL
 
 	telegraf.Parser
@@ -109,6 +137,21 @@ func (h *HTTPListenerV2) Init() error {
 	if h.SuccessCode == 0 {
 		h.SuccessCode = http.StatusNoContent
 	}
+	if h.SuccessBody != "" && (h.SuccessCode == http.StatusNoContent || h.SuccessCode == http.StatusNotModified) {
+		return fmt.Errorf("http_success_body not allowed for http_success_code %d", h.SuccessCode)
+	}
+
+	if h.DeadLetterDir != "" {
+		if err := os.MkdirAll(h.DeadLetterDir, 0750); err != nil {
+			return fmt.Errorf("creating dead-letter directory failed: %w", err)
+		}
+		if h.DeadLetterMaxFiles <= 0 {
+			h.DeadLetterMaxFiles = defaultDeadLetterMaxFiles
+		}
+		if h.DeadLetterMaxSize == 0 {
+			h.DeadLetterMaxSize = config.Size(defaultDeadLetterMaxSize)
+		}
+	}
 
 	return nil
 }
@@ -171,6 +214,9 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 	if h.WriteTimeout < config.Duration(time.Second) {
 		h.WriteTimeout = config.Duration(time.Second * 10)
 	}
+	if h.ShutdownTimeout <= 0 {
+		h.ShutdownTimeout = config.Duration(time.Second * 5)
+	}
 
 	// Append h.Path to h.Paths
 	if h.Path != "" && !choice.Contains(h.Path, h.Paths) {
@@ -180,12 +226,13 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 	h.acc = acc
 
 	server := h.createHTTPServer()
+	h.server = server
 
 	h.wg.Add(1)
 	go func() {
 		defer h.wg.Done()
 		if err := server.Serve(h.listener); err != nil {
-			if !errors.Is(err, net.ErrClosed) {
+			if !errors.Is(err, net.ErrClosed) && !errors.Is(err, http.ErrServerClosed) {
 				h.Log.Errorf("Serve failed: %v", err)
 			}
 			close(h.close)
@@ -202,7 +249,16 @@ func (*HTTPListenerV2) Gather(telegraf.Accumulator) error {
 }
 
 func (h *HTTPListenerV2) Stop() {
-	if h.listener != nil {
+	// Stop accepting new connections and let in-flight requests finish
+	if h.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.ShutdownTimeout))
+		defer cancel()
+		if err := h.server.Shutdown(ctx); err != nil {
+			h.Log.Warnf("Draining in-flight requests failed: %v", err)
+			h.server.Close()
+		}
+		h.server = nil
+	} else if h.listener != nil {
 		h.listener.Close()
 	}
 	h.wg.Wait()
@@ -264,6 +320,14 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	// Check the content type if required
+	if h.RequiredContentType != "" && !h.isRequiredContentType(req.Header.Get("Content-Type")) {
+		if err := unsupportedMediaType(res); err != nil {
+			h.Log.Debugf("error in unsupported-media-type: %v", err)
+		}
+		return
+	}
+
 	var bytes []byte
 	var ok bool
 
@@ -280,7 +344,12 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 
 	metrics, err := h.Parse(bytes)
 	if err != nil {
-		h.Log.Debugf("Parse error: %s", err.Error())
+		h.Log.Errorf("Parse error: %s", err.Error())
+		if h.DeadLetterDir != "" {
+			if err := h.writeDeadLetter(bytes); err != nil {
+				h.Log.Errorf("Writing dead-letter file failed: %v", err)
+			}
+		}
 		if err := badRequest(res); err != nil {
 			h.Log.Debugf("error in bad-request: %v", err)
 		}
@@ -293,6 +362,11 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		})
 	}
 
+	var source string
+	if h.SourceTag != "" {
+		source = remoteHost(req.RemoteAddr)
+	}
+
 	for _, m := range metrics {
 		for headerName, measurementName := range h.HTTPHeaderTags {
 			headerValues := req.Header.Get(headerName)
@@ -305,10 +379,22 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 			m.AddTag(pathTag, req.URL.Path)
 		}
 
+		if source != "" {
+			m.AddTag(h.SourceTag, source)
+		}
+
 		h.acc.AddMetric(m)
 	}
 
+	if h.SuccessBody != "" && json.Valid([]byte(h.SuccessBody)) {
+		res.Header().Set("Content-Type", "application/json")
+	}
 	res.WriteHeader(h.SuccessCode)
+	if h.SuccessBody != "" {
+		if _, err := res.Write([]byte(h.SuccessBody)); err != nil {
+			h.Log.Debugf("error in writing success body: %v", err)
+		}
+	}
 }
 
 func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request) ([]byte, bool) {
@@ -336,7 +422,31 @@ func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request)
 		return bytes, true
 	case "snappy":
 		defer req.Body.Close()
-		bytes, err := io.ReadAll(req.Body)
+
+		// Payloads in the snappy framing format start with a stream identifier
+		// and need to be decoded using the snappy reader
+		br := bufio.NewReader(req.Body)
+		if header, err := br.Peek(len(snappyStreamIdentifier)); err == nil && string(header) == snappyStreamIdentifier {
+			maxReader := http.MaxBytesReader(res, io.NopCloser(snappy.NewReader(br)), int64(h.MaxBodySize))
+			decoded, err := io.ReadAll(maxReader)
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					if err := tooLarge(res); err != nil {
+						h.Log.Debugf("error in too-large: %v", err)
+					}
+					return nil, false
+				}
+				h.Log.Debug(err.Error())
+				if err := badRequest(res); err != nil {
+					h.Log.Debugf("error in bad-request: %v", err)
+				}
+				return nil, false
+			}
+			return decoded, true
+		}
+
+		payload, err := io.ReadAll(br)
 		if err != nil {
 			h.Log.Debug(err.Error())
 			if err := badRequest(res); err != nil {
@@ -345,7 +455,7 @@ func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request)
 			return nil, false
 		}
 		// snappy block format is only supported by decode/encode not snappy reader/writer
-		bytes, err = snappy.Decode(nil, bytes)
+		decoded, err := snappy.Decode(nil, payload)
 		if err != nil {
 			h.Log.Debug(err.Error())
 			if err := badRequest(res); err != nil {
@@ -353,8 +463,8 @@ func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request)
 			}
 			return nil, false
 		}
-		return bytes, true
-	default:
+		return decoded, true
+	case "", "identity":
 		defer req.Body.Close()
 		bytes, err := io.ReadAll(req.Body)
 		if err != nil {
@@ -365,6 +475,13 @@ func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request)
 			return nil, false
 		}
 		return bytes, true
+	default:
+		req.Body.Close()
+		h.Log.Debugf("Unsupported content encoding %q", encoding)
+		if err := badRequest(res); err != nil {
+			h.Log.Debugf("error in bad-request: %v", err)
+		}
+		return nil, false
 	}
 }
 
@@ -383,6 +500,58 @@ func (h *HTTPListenerV2) collectQuery(res http.ResponseWriter, req *http.Request
 	return []byte(query), true
 }
 
+// writeDeadLetter persists the unparsable request body to the dead-letter
+// directory, truncated to the configured maximum size. The oldest files are
+// removed to keep at most the configured number of files.
+func (h *HTTPListenerV2) writeDeadLetter(data []byte) error {
+	h.deadLetterMu.Lock()
+	defer h.deadLetterMu.Unlock()
+
+	entries, err := os.ReadDir(h.DeadLetterDir)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "request-") && strings.HasSuffix(entry.Name(), ".body") {
+			files = append(files, entry.Name())
+		}
+	}
+
+	// The names start with the timestamp so sorting yields the oldest first
+	slices.Sort(files)
+	for len(files) >= h.DeadLetterMaxFiles {
+		if err := os.Remove(filepath.Join(h.DeadLetterDir, files[0])); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+
+	if len(data) > int(h.DeadLetterMaxSize) {
+		data = data[:h.DeadLetterMaxSize]
+	}
+
+	pattern := "request-" + h.timeFunc().UTC().Format("20060102T150405.000000000") + "-*.body"
+	f, err := os.CreateTemp(h.DeadLetterDir, pattern)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// remoteHost strips the port from the remote address of a request
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
 func tooLarge(res http.ResponseWriter) error {
 	res.Header().Set("Content-Type", "application/json")
 	res.WriteHeader(http.StatusRequestEntityTooLarge)
@@ -397,6 +566,13 @@ func methodNotAllowed(res http.ResponseWriter) error {
 	return err
 }
 
+func unsupportedMediaType(res http.ResponseWriter) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusUnsupportedMediaType)
+	_, err := res.Write([]byte(`{"error":"http: unsupported media type"}`))
+	return err
+}
+
 func badRequest(res http.ResponseWriter) error {
 	res.Header().Set("Content-Type", "application/json")
 	res.WriteHeader(http.StatusBadRequest)
@@ -404,6 +580,16 @@ func badRequest(res http.ResponseWriter) error {
 	return err
 }
 
+// isRequiredContentType checks if the media type of the given content-type
+// header matches the required one ignoring any parameters like the charset
+func (h *HTTPListenerV2) isRequiredContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(mediaType, h.RequiredContentType)
+}
+
 func (h *HTTPListenerV2) authenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
 	if h.BasicUsername != "" && h.BasicPassword != "" {
 		reqUsername, reqPassword, ok := req.BasicAuth()
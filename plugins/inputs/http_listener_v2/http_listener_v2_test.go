@@ -6,10 +6,12 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -212,6 +214,71 @@ func TestWriteHTTPBasicAuth(t *testing.T) {
 	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
 }
 
+func TestStopDrainsInFlightRequests(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.ShutdownTimeout = config.Duration(5 * time.Second)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+
+	// Start a slow write only sending part of the body
+	reader, writer := io.Pipe()
+	type result struct {
+		status int
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Post(createURL(listener, "http", "/write", ""), "", reader)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		resp.Body.Close()
+		done <- result{status: resp.StatusCode}
+	}()
+	_, err = writer.Write([]byte(testMsg[:10]))
+	require.NoError(t, err)
+
+	// Give the server time to accept the connection
+	time.Sleep(100 * time.Millisecond)
+
+	// Stop the plugin while the request is in-flight
+	stopped := make(chan struct{})
+	go func() {
+		listener.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		require.Fail(t, "stopped before in-flight request finished")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Finish the request and make sure it succeeds before stop returns
+	_, err = writer.Write([]byte(testMsg[10:]))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	res := <-done
+	require.NoError(t, res.err)
+	require.Equal(t, http.StatusNoContent, res.status)
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "stopping did not finish within the shutdown timeout")
+	}
+
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01"},
+	)
+}
+
 func TestWriteHTTP(t *testing.T) {
 	listener, err := newTestHTTPListenerV2()
 	require.NoError(t, err)
@@ -286,6 +353,62 @@ func TestWriteHTTPWithPathTag(t *testing.T) {
 	)
 }
 
+func TestWriteHTTPWithSourceTag(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.ServiceAddress = "tcp://127.0.0.1:0"
+	listener.SourceTag = "source"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post single message to listener
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(testMsgNoNewline))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01", "source": "127.0.0.1"},
+	)
+}
+
+func TestWriteHTTPRequiredContentType(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.RequiredContentType = "text/plain"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post with the wrong and without content type
+	for _, contentType := range []string{"application/json", ""} {
+		resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), contentType, bytes.NewBufferString(testMsgNoNewline))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.EqualValues(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	}
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// post with the required content type
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "text/plain; charset=utf-8", bytes.NewBufferString(testMsgNoNewline))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01"},
+	)
+}
+
 func TestWriteHTTPWithReturnCode(t *testing.T) {
 	listener, err := newTestHTTPListenerV2()
 	require.NoError(t, err)
@@ -303,6 +426,35 @@ func TestWriteHTTPWithReturnCode(t *testing.T) {
 	require.EqualValues(t, 200, resp.StatusCode)
 }
 
+func TestWriteHTTPWithReturnBody(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.SuccessCode = 200
+	listener.SuccessBody = `{"status":"ok"}`
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post single message to listener
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(testMsgNoNewline))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 200, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.JSONEq(t, `{"status":"ok"}`, string(body))
+}
+
+func TestReturnBodyWithNoContent(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.SuccessBody = `{"status":"ok"}`
+	require.ErrorContains(t, listener.Init(), "http_success_body not allowed for http_success_code 204")
+}
+
 // http listener should add request path as configured path_tag (trimming it before)
 func TestWriteHTTPWithMultiplePaths(t *testing.T) {
 	listener, err := newTestHTTPListenerV2()
@@ -486,6 +638,58 @@ func TestWriteHTTPSnappyData(t *testing.T) {
 	}
 }
 
+// test that writing data in the snappy framing format works
+func TestWriteHTTPSnappyFramedData(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	var buf bytes.Buffer
+	w := snappy.NewBufferedWriter(&buf)
+	_, err = w.Write([]byte(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req, err := http.NewRequest("POST", createURL(listener, "http", "/write", ""), &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "snappy")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01"},
+	)
+}
+
+func TestWriteHTTPUnknownEncoding(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	req, err := http.NewRequest("POST", createURL(listener, "http", "/write", ""), bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "br")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 400, resp.StatusCode)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
 // writes 25,000 metrics to the listener with 10 different writers
 func TestWriteHTTPHighTraffic(t *testing.T) {
 	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
@@ -559,6 +763,66 @@ func TestWriteHTTPInvalid(t *testing.T) {
 	require.EqualValues(t, 400, resp.StatusCode)
 }
 
+func TestWriteHTTPInvalidLogsError(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	logger := &testutil.CaptureLogger{}
+	listener.Log = logger
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post a body with multiple invalid lines to the listener
+	body := strings.Repeat(badMsg, 3)
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 400, resp.StatusCode)
+
+	errs := logger.Errors()
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0], "Parse error")
+}
+
+func TestWriteHTTPInvalidDeadLetter(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.DeadLetterDir = filepath.Join(t.TempDir(), "dead-letter")
+	listener.DeadLetterMaxFiles = 2
+	listener.DeadLetterMaxSize = config.Size(8)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post bad messages to the listener, exceeding the maximum file count
+	for range 3 {
+		resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(badMsg))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.EqualValues(t, 400, resp.StatusCode)
+	}
+
+	// post a valid message that must not be written
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+
+	entries, err := os.ReadDir(listener.DeadLetterDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		require.True(t, strings.HasPrefix(entry.Name(), "request-"))
+		content, err := os.ReadFile(filepath.Join(listener.DeadLetterDir, entry.Name()))
+		require.NoError(t, err)
+		require.Equal(t, badMsg[:8], string(content))
+	}
+}
+
 func TestWriteHTTPEmpty(t *testing.T) {
 	listener, err := newTestHTTPListenerV2()
 	require.NoError(t, err)
@@ -2,17 +2,24 @@
 package http_response
 
 import (
+	"bytes"
+	"context"
+	cryptotls "crypto/tls"
 	_ "embed"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -30,10 +37,33 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// reservedFields are the fields set by the plugin itself which must not be
+// overwritten by the named capture groups of the body regex
+var reservedFields = []string{
+	"body_exact_match",
+	"cert_chain_length",
+	"connect_time",
+	"content_length",
+	"dns_time",
+	"http_response_code",
+	"redirect_count",
+	"response_status_code_match",
+	"response_string_match",
+	"response_time",
+	"result_code",
+	"result_type",
+	"time_to_first_byte",
+	"tls_handshake_time",
+}
+
 const (
 	// defaultResponseBodyMaxSize is the default maximum response body size, in bytes.
 	// if the response body is over this size, we will raise a body_read_error.
 	defaultResponseBodyMaxSize = 32 * 1024 * 1024
+
+	// maxRedirects is the number of redirects followed before giving up,
+	// the same as the default of the net/http client
+	maxRedirects = 10
 )
 
 type HTTPResponse struct {
@@ -47,13 +77,24 @@ type HTTPResponse struct {
 	HTTPHeaderTags  map[string]string   `toml:"http_header_tags"`
 	Headers         map[string]string   `toml:"headers"`
 	FollowRedirects bool                `toml:"follow_redirects"`
+	RedirectHops    bool                `toml:"redirect_hops"`
 	// Absolute path to file with Bearer token
 	BearerToken         string      `toml:"bearer_token"`
 	ResponseBodyField   string      `toml:"response_body_field"`
 	ResponseBodyMaxSize config.Size `toml:"response_body_max_size"`
+	ResponseBodyRegex   string      `toml:"response_body_regex"`
 	ResponseStringMatch string      `toml:"response_string_match"`
-	ResponseStatusCode  int         `toml:"response_status_code"`
-	Interface           string      `toml:"interface"`
+	// Multiple patterns all required to match the body
+	ResponseStringMatches []string `toml:"response_string_matches"`
+	ResponseStatusCode    int      `toml:"response_status_code"`
+	SuccessStatusRange    []string `toml:"success_status_range"`
+	ExpectedBodyFile      string   `toml:"expected_body_file"`
+	ExpectedBodyCompare   string   `toml:"expected_body_compare"`
+	Interface             string   `toml:"interface"`
+	CollectTLSDetails     bool     `toml:"collect_tls_details"`
+	IPVersion             string   `toml:"ip_version"`
+	Warmup                bool     `toml:"warmup"`
+	HTTP2                 bool     `toml:"http2"`
 	// HTTP Basic Auth Credentials
 	Username config.Secret `toml:"username"`
 	Password config.Secret `toml:"password"`
@@ -62,8 +103,17 @@ type HTTPResponse struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	compiledStringMatch *regexp.Regexp
-	clients             []client
+	compiledStringMatch   *regexp.Regexp
+	compiledStringMatches []*regexp.Regexp
+	compiledBodyRegex     *regexp.Regexp
+	expectedBody          []byte
+	successStatus         []statusRange
+	clients               []client
+}
+
+// statusRange is an inclusive range of HTTP status codes
+type statusRange struct {
+	min, max int
 }
 
 type client struct {
@@ -89,6 +139,64 @@ func (h *HTTPResponse) Init() error {
 			return fmt.Errorf("failed to compile regular expression %q: %w", h.ResponseStringMatch, err)
 		}
 	}
+	h.compiledStringMatches = make([]*regexp.Regexp, 0, len(h.ResponseStringMatches))
+	for _, pattern := range h.ResponseStringMatches {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("failed to compile regular expression %q: %w", pattern, err)
+		}
+		h.compiledStringMatches = append(h.compiledStringMatches, re)
+	}
+
+	// Compile the regex for extracting fields from the body
+	if h.ResponseBodyRegex != "" {
+		var err error
+		h.compiledBodyRegex, err = regexp.Compile(h.ResponseBodyRegex)
+		if err != nil {
+			return fmt.Errorf("failed to compile regular expression %q: %w", h.ResponseBodyRegex, err)
+		}
+		if !slices.ContainsFunc(h.compiledBodyRegex.SubexpNames(), func(name string) bool { return name != "" }) {
+			return fmt.Errorf("regular expression %q does not contain a named capture group", h.ResponseBodyRegex)
+		}
+		for _, name := range h.compiledBodyRegex.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if slices.Contains(reservedFields, name) || strings.HasPrefix(name, "response_string_match_") || name == h.ResponseBodyField {
+				return fmt.Errorf("named capture group %q of regular expression %q conflicts with a field of the plugin", name, h.ResponseBodyRegex)
+			}
+		}
+	}
+
+	// Read the expected body for comparing the response against
+	if h.ExpectedBodyFile != "" {
+		switch h.ExpectedBodyCompare {
+		case "":
+			h.ExpectedBodyCompare = "exact"
+		case "exact", "normalized":
+		default:
+			return fmt.Errorf("invalid expected_body_compare %q", h.ExpectedBodyCompare)
+		}
+
+		expected, err := os.ReadFile(h.ExpectedBodyFile)
+		if err != nil {
+			return fmt.Errorf("reading expected body file failed: %w", err)
+		}
+		if h.ExpectedBodyCompare == "normalized" {
+			expected = normalizeBody(expected)
+		}
+		h.expectedBody = expected
+	}
+
+	// Parse the status codes considered as success
+	h.successStatus = make([]statusRange, 0, len(h.SuccessStatusRange))
+	for _, spec := range h.SuccessStatusRange {
+		r, err := parseStatusRange(spec)
+		if err != nil {
+			return err
+		}
+		h.successStatus = append(h.successStatus, r)
+	}
 
 	// Set default values
 	if h.ResponseTimeout < config.Duration(time.Second) {
@@ -98,6 +206,25 @@ func (h *HTTPResponse) Init() error {
 		h.Method = "GET"
 	}
 
+	if h.RedirectHops && !h.FollowRedirects {
+		return errors.New("redirect_hops requires follow_redirects to be enabled")
+	}
+
+	// Sending the request twice is only safe for methods without side effects
+	if h.Warmup {
+		switch strings.ToUpper(h.Method) {
+		case "GET", "HEAD", "OPTIONS":
+		default:
+			return fmt.Errorf("warmup is not supported for method %q", h.Method)
+		}
+	}
+
+	switch h.IPVersion {
+	case "", "any", "4", "6":
+	default:
+		return fmt.Errorf("invalid ip_version %q", h.IPVersion)
+	}
+
 	if len(h.URLs) == 0 {
 		if h.Address == "" {
 			h.URLs = []string{"http://localhost"}
@@ -135,8 +262,19 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 		var fields map[string]interface{}
 		var tags map[string]string
 
+		// Perform an untimed request to establish the connection
+		if h.Warmup {
+			h.warmup(c)
+		}
+
 		// Gather data
-		fields, tags, err := h.httpGather(c)
+		fields, tags, hops, err := h.httpGather(c)
+		if h.Warmup {
+			// Do not keep connections open in between gathering cycles
+			if cl, ok := c.httpClient.(interface{ CloseIdleConnections() }); ok {
+				cl.CloseIdleConnections()
+			}
+		}
 		if err != nil {
 			acc.AddError(err)
 			continue
@@ -144,6 +282,24 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 
 		// Add metrics
 		acc.AddFields("http_response", fields, tags)
+		if h.RedirectHops {
+			for i, hop := range hops {
+				acc.AddFields(
+					"http_response_hop",
+					map[string]interface{}{
+						"hop":                i + 1,
+						"http_response_code": hop.status,
+						"response_time":      hop.duration,
+					},
+					map[string]string{
+						"server":      c.address,
+						"method":      h.Method,
+						"url":         hop.url,
+						"status_code": strconv.Itoa(hop.status),
+					},
+				)
+			}
+		}
 	}
 
 	return nil
@@ -182,17 +338,30 @@ func (h *HTTPResponse) createHTTPClient(address url.URL) (*http.Client, error) {
 		}
 	}
 
+	// Restrict the address family used for connecting if requested
+	dialContext := dialer.DialContext
+	if h.IPVersion == "4" || h.IPVersion == "6" {
+		network := "tcp" + h.IPVersion
+		dialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	// Keep the connection of the warmup request alive for the measured one
 	client := &http.Client{
 		Transport: &http.Transport{
 			Proxy:             getProxyFunc(h.HTTPProxy),
-			DialContext:       dialer.DialContext,
-			DisableKeepAlives: true,
+			DialContext:       dialContext,
+			DisableKeepAlives: !h.Warmup,
 			TLSClientConfig:   tlsCfg,
+			ForceAttemptHTTP2: h.HTTP2,
 		},
 		Timeout: time.Duration(h.ResponseTimeout),
 	}
 
-	if !h.FollowRedirects {
+	if h.FollowRedirects {
+		client.CheckRedirect = countRedirects
+	} else {
 		client.CheckRedirect = func(*http.Request, []*http.Request) error {
 			return http.ErrUseLastResponse
 		}
@@ -207,6 +376,42 @@ func (h *HTTPResponse) createHTTPClient(address url.URL) (*http.Client, error) {
 	return client, nil
 }
 
+type redirectTraceKey struct{}
+
+// redirectTrace records the redirects followed for a request
+type redirectTrace struct {
+	count     int
+	hops      []redirectHop
+	hopsStart time.Time
+}
+
+// redirectHop is a single response of a redirect chain
+type redirectHop struct {
+	url      string
+	status   int
+	duration float64
+}
+
+// countRedirects records the redirects followed in the trace stored in the
+// request context while keeping the limit of the default policy
+func countRedirects(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if trace, ok := req.Context().Value(redirectTraceKey{}).(*redirectTrace); ok {
+		trace.count = len(via)
+		if req.Response != nil {
+			trace.hops = append(trace.hops, redirectHop{
+				url:      via[len(via)-1].URL.String(),
+				status:   req.Response.StatusCode,
+				duration: time.Since(trace.hopsStart).Seconds(),
+			})
+		}
+		trace.hopsStart = time.Now()
+	}
+	return nil
+}
+
 func localAddress(interfaceName string, address url.URL) (net.Addr, error) {
 	i, err := net.InterfaceByName(interfaceName)
 	if err != nil {
@@ -257,6 +462,27 @@ func isIPNetInIPv6(address *net.IPNet) bool {
 	return err == nil && ipAddr.ToIPv6() != nil
 }
 
+// parseStatusRange parses a single status code like "301" or an inclusive
+// range of codes like "200-299"
+func parseStatusRange(spec string) (statusRange, error) {
+	lower, upper, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		upper = lower
+	}
+	minCode, err := strconv.Atoi(strings.TrimSpace(lower))
+	if err != nil {
+		return statusRange{}, fmt.Errorf("invalid success_status_range %q: %w", spec, err)
+	}
+	maxCode, err := strconv.Atoi(strings.TrimSpace(upper))
+	if err != nil {
+		return statusRange{}, fmt.Errorf("invalid success_status_range %q: %w", spec, err)
+	}
+	if minCode > maxCode {
+		return statusRange{}, fmt.Errorf("invalid success_status_range %q: lower bound exceeds upper bound", spec)
+	}
+	return statusRange{min: minCode, max: maxCode}, nil
+}
+
 func setResult(resultString string, fields map[string]interface{}, tags map[string]string) {
 	resultCodes := map[string]int{
 		"success":                       0,
@@ -304,59 +530,97 @@ func setError(err error, fields map[string]interface{}, tags map[string]string)
 	return nil
 }
 
-// HTTPGather gathers all fields and returns any errors it encounters
-func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string]string, error) {
+// HTTPGather gathers all fields and the hops of followed redirects and
+// returns any errors it encounters
+func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string]string, []redirectHop, error) {
 	// Prepare fields and tags
 	fields := make(map[string]interface{})
 	tags := map[string]string{"server": cl.address, "method": h.Method}
 
-	var body io.Reader
-	if h.Body != "" {
-		body = strings.NewReader(h.Body)
-	} else if len(h.BodyForm) != 0 {
-		values := url.Values{}
-		for k, vs := range h.BodyForm {
-			for _, v := range vs {
-				values.Add(k, v)
-			}
-		}
-		body = strings.NewReader(values.Encode())
-	}
-
-	request, err := http.NewRequest(h.Method, cl.address, body)
+	request, err := h.newRequest(cl.address)
 	if err != nil {
-		return nil, nil, err
-	}
-
-	if _, uaPresent := h.Headers["User-Agent"]; !uaPresent {
-		request.Header.Set("User-Agent", internal.ProductToken())
-	}
-
-	if h.BearerToken != "" {
-		token, err := os.ReadFile(h.BearerToken)
-		if err != nil {
-			return nil, nil, err
-		}
-		bearer := "Bearer " + strings.Trim(string(token), "\n")
-		request.Header.Add("Authorization", bearer)
+		return nil, nil, nil, err
+	}
+
+	// Trace the phases of the request, i.e. DNS lookup, connecting and the
+	// TLS handshake, and determine the address family of the connection
+	var mu sync.Mutex
+	var dnsStart, connectStart, tlsStart time.Time
+	var start time.Time
+	phases := make(map[string]interface{})
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			if info.Err == nil && !dnsStart.IsZero() {
+				phases["dns_time"] = time.Since(dnsStart).Seconds()
+			}
+		},
+		ConnectStart: func(string, string) {
+			mu.Lock()
+			defer mu.Unlock()
+			connectStart = time.Now()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil && !connectStart.IsZero() {
+				phases["connect_time"] = time.Since(connectStart).Seconds()
+			}
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ cryptotls.ConnectionState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil && !tlsStart.IsZero() {
+				phases["tls_handshake_time"] = time.Since(tlsStart).Seconds()
+			}
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			phases["time_to_first_byte"] = time.Since(start).Seconds()
+		},
 	}
-
-	for key, val := range h.Headers {
-		request.Header.Add(key, val)
-		if key == "Host" {
-			request.Host = val
+	if h.IPVersion != "" {
+		trace.GotConn = func(info httptrace.GotConnInfo) {
+			if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+				if addr.IP.To4() != nil {
+					tags["ip_version"] = "4"
+				} else {
+					tags["ip_version"] = "6"
+				}
+			}
 		}
 	}
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), trace))
 
-	if err := h.setRequestAuth(request); err != nil {
-		return nil, nil, err
+	// Record the redirects followed for this request
+	redirects := &redirectTrace{}
+	if h.FollowRedirects {
+		request = request.WithContext(context.WithValue(request.Context(), redirectTraceKey{}, redirects))
 	}
 
 	// Start Timer
-	start := time.Now()
+	start = time.Now()
+	redirects.hopsStart = start
 	resp, err := cl.httpClient.Do(request)
 	responseTime := time.Since(start).Seconds()
 
+	// Add the durations of the phases completed
+	mu.Lock()
+	maps.Copy(fields, phases)
+	mu.Unlock()
+
 	// If an error in returned, it means we are dealing with a network error, as
 	// HTTP error codes do not generate errors in the net/http library
 	if err != nil {
@@ -369,7 +633,7 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 			setResult("connection_failed", fields, tags)
 		}
 
-		return fields, tags, nil
+		return fields, tags, redirects.hops, nil
 	}
 
 	if _, ok := fields["response_time"]; !ok {
@@ -388,6 +652,30 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		}
 	}
 
+	// Add the details of the leaf certificate presented by the server
+	if h.CollectTLSDetails && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		leaf := resp.TLS.PeerCertificates[0]
+		tags["issuer"] = leaf.Issuer.String()
+		tags["subject"] = leaf.Subject.String()
+		fields["cert_chain_length"] = len(resp.TLS.PeerCertificates)
+	}
+
+	// Add the redirects followed to get the response
+	if h.FollowRedirects {
+		fields["redirect_count"] = redirects.count
+		tags["final_url"] = resp.Request.URL.String()
+		redirects.hops = append(redirects.hops, redirectHop{
+			url:      resp.Request.URL.String(),
+			status:   resp.StatusCode,
+			duration: time.Since(redirects.hopsStart).Seconds(),
+		})
+	}
+
+	// Add the negotiated protocol version
+	if h.HTTP2 {
+		tags["http_version"] = resp.Proto
+	}
+
 	// Set log the HTTP response code
 	tags["status_code"] = strconv.Itoa(resp.StatusCode)
 	fields["http_response_code"] = resp.StatusCode
@@ -399,10 +687,10 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 	// Check first if the response body size exceeds the limit.
 	if err == nil && int64(len(bodyBytes)) > int64(h.ResponseBodyMaxSize) {
 		h.setBodyReadError("The body of the HTTP Response is too large", bodyBytes, fields, tags)
-		return fields, tags, nil
+		return fields, tags, redirects.hops, nil
 	} else if err != nil {
 		h.setBodyReadError("Failed to read body of HTTP Response : "+err.Error(), bodyBytes, fields, tags)
-		return fields, tags, nil
+		return fields, tags, redirects.hops, nil
 	}
 
 	// Add the body of the response if expected
@@ -410,17 +698,44 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		// Check that the content of response contains only valid utf-8 characters.
 		if !utf8.Valid(bodyBytes) {
 			h.setBodyReadError("The body of the HTTP Response is not a valid utf-8 string", bodyBytes, fields, tags)
-			return fields, tags, nil
+			return fields, tags, redirects.hops, nil
 		}
 		fields[h.ResponseBodyField] = string(bodyBytes)
 	}
 	fields["content_length"] = len(bodyBytes)
 
+	// Extract the named capture groups of the first match as fields
+	h.extractBodyFields(bodyBytes, fields)
+
+	// Compare the body against the expected content
+	if h.ExpectedBodyFile != "" {
+		actual := bodyBytes
+		if h.ExpectedBodyCompare == "normalized" {
+			actual = normalizeBody(actual)
+		}
+		if bytes.Equal(actual, h.expectedBody) {
+			fields["body_exact_match"] = 1
+		} else {
+			fields["body_exact_match"] = 0
+		}
+	}
+
 	var success = true
 
-	// Check the response for a regex
-	if h.ResponseStringMatch != "" {
-		if h.compiledStringMatch.Match(bodyBytes) {
+	// Check the response for a regex, all patterns must match
+	if h.ResponseStringMatch != "" || len(h.compiledStringMatches) > 0 {
+		matched := h.ResponseStringMatch == "" || h.compiledStringMatch.Match(bodyBytes)
+		for i, re := range h.compiledStringMatches {
+			field := "response_string_match_" + strconv.Itoa(i)
+			if re.Match(bodyBytes) {
+				fields[field] = 1
+			} else {
+				matched = false
+				fields[field] = 0
+			}
+		}
+
+		if matched {
 			fields["response_string_match"] = 1
 		} else {
 			success = false
@@ -440,11 +755,90 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		}
 	}
 
+	// Check the response status code against the codes considered as success
+	if len(h.successStatus) > 0 && success {
+		inRange := slices.ContainsFunc(h.successStatus, func(r statusRange) bool {
+			return resp.StatusCode >= r.min && resp.StatusCode <= r.max
+		})
+		if !inRange {
+			success = false
+			setResult("response_status_code_mismatch", fields, tags)
+		}
+	}
+
 	if success {
 		setResult("success", fields, tags)
 	}
 
-	return fields, tags, nil
+	return fields, tags, redirects.hops, nil
+}
+
+// newRequest creates the request to the given address including the
+// configured body, headers and authentication
+func (h *HTTPResponse) newRequest(address string) (*http.Request, error) {
+	var body io.Reader
+	if h.Body != "" {
+		body = strings.NewReader(h.Body)
+	} else if len(h.BodyForm) != 0 {
+		values := url.Values{}
+		for k, vs := range h.BodyForm {
+			for _, v := range vs {
+				values.Add(k, v)
+			}
+		}
+		body = strings.NewReader(values.Encode())
+	}
+
+	request, err := http.NewRequest(h.Method, address, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, uaPresent := h.Headers["User-Agent"]; !uaPresent {
+		request.Header.Set("User-Agent", internal.ProductToken())
+	}
+
+	if h.BearerToken != "" {
+		token, err := os.ReadFile(h.BearerToken)
+		if err != nil {
+			return nil, err
+		}
+		bearer := "Bearer " + strings.Trim(string(token), "\n")
+		request.Header.Add("Authorization", bearer)
+	}
+
+	for key, val := range h.Headers {
+		request.Header.Add(key, val)
+		if key == "Host" {
+			request.Host = val
+		}
+	}
+
+	if err := h.setRequestAuth(request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// warmup performs a request to the server without measuring it so that
+// the measured request can reuse the established connection
+func (h *HTTPResponse) warmup(cl client) {
+	request, err := h.newRequest(cl.address)
+	if err != nil {
+		h.Log.Debugf("Creating warmup request for %s failed: %v", cl.address, err)
+		return
+	}
+
+	resp, err := cl.httpClient.Do(request)
+	if err != nil {
+		h.Log.Debugf("Warmup request to %s failed: %v", cl.address, err)
+		return
+	}
+
+	// Drain the body to allow reusing the connection
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
 }
 
 // Set result in case of a body read error
@@ -452,9 +846,46 @@ func (h *HTTPResponse) setBodyReadError(errorMsg string, bodyBytes []byte, field
 	h.Log.Debug(errorMsg)
 	setResult("body_read_error", fields, tags)
 	fields["content_length"] = len(bodyBytes)
-	if h.ResponseStringMatch != "" {
+
+	if h.ResponseStringMatch != "" || len(h.compiledStringMatches) > 0 {
 		fields["response_string_match"] = 0
 	}
+	for i := range h.compiledStringMatches {
+		fields["response_string_match_"+strconv.Itoa(i)] = 0
+	}
+	if h.ExpectedBodyFile != "" {
+		fields["body_exact_match"] = 0
+	}
+}
+
+// extractBodyFields adds the named capture groups of the first match of the
+// body regex as fields
+func (h *HTTPResponse) extractBodyFields(body []byte, fields map[string]interface{}) {
+	if h.compiledBodyRegex == nil {
+		return
+	}
+
+	match := h.compiledBodyRegex.FindSubmatchIndex(body)
+	if match == nil {
+		return
+	}
+	for i, name := range h.compiledBodyRegex.SubexpNames() {
+		if name == "" || match[2*i] < 0 {
+			continue
+		}
+		value := body[match[2*i]:match[2*i+1]]
+		if !utf8.Valid(value) {
+			h.Log.Debugf("Value of capture group %q is not a valid utf-8 string", name)
+			continue
+		}
+		fields[name] = string(value)
+	}
+}
+
+// normalizeBody removes leading and trailing whitespace and collapses all
+// other whitespace sequences as well as line endings into a single space
+func normalizeBody(body []byte) []byte {
+	return bytes.Join(bytes.Fields(body), []byte(" "))
 }
 
 func (h *HTTPResponse) setRequestAuth(request *http.Request) error {
@@ -1,6 +1,7 @@
 package http_response
 
 import (
+	cryptotls "crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -8,6 +9,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -561,6 +566,78 @@ func TestInterface(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, absentFields, nil)
 }
 
+func TestRedirectHops(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/redirect"},
+		Method:          "GET",
+		ResponseTimeout: config.Duration(time.Second * 20),
+		FollowRedirects: true,
+		RedirectHops:    true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"http_response_hop",
+			map[string]string{
+				"server":      ts.URL + "/redirect",
+				"method":      "GET",
+				"url":         ts.URL + "/redirect",
+				"status_code": "301",
+			},
+			map[string]interface{}{
+				"hop":                1,
+				"http_response_code": http.StatusMovedPermanently,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"http_response_hop",
+			map[string]string{
+				"server":      ts.URL + "/redirect",
+				"method":      "GET",
+				"url":         ts.URL + "/good",
+				"status_code": "200",
+			},
+			map[string]interface{}{
+				"hop":                2,
+				"http_response_code": http.StatusOK,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "http_response_hop" {
+			continue
+		}
+		rt, found := m.GetField("response_time")
+		require.True(t, found)
+		require.GreaterOrEqual(t, rt, 0.0)
+		m.RemoveField("response_time")
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestRedirectHopsRequiresFollowRedirects(t *testing.T) {
+	h := &HTTPResponse{
+		Log:          testutil.Logger{},
+		URLs:         []string{"http://localhost"},
+		RedirectHops: true,
+	}
+	require.ErrorContains(t, h.Init(), "requires follow_redirects")
+}
+
 func TestRedirects(t *testing.T) {
 	mux := setUpTestMux()
 	ts := httptest.NewServer(mux)
@@ -631,6 +708,36 @@ func TestRedirects(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, absentFields, absentTags)
 }
 
+func TestRedirectCount(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/redirect", ts.URL + "/good"},
+		ResponseTimeout: config.Duration(time.Second * 20),
+		FollowRedirects: true,
+	}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Len(t, acc.Metrics, 2)
+
+	for _, m := range acc.Metrics {
+		require.Equal(t, ts.URL+"/good", m.Tags["final_url"])
+		switch m.Tags["server"] {
+		case ts.URL + "/redirect":
+			require.Equal(t, 1, m.Fields["redirect_count"])
+		case ts.URL + "/good":
+			require.Equal(t, 0, m.Fields["redirect_count"])
+		default:
+			require.Failf(t, "unexpected server", "server %q", m.Tags["server"])
+		}
+	}
+}
+
 func TestMethod(t *testing.T) {
 	mux := setUpTestMux()
 	ts := httptest.NewServer(mux)
@@ -840,6 +947,162 @@ func TestStringMatch(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
 }
 
+func TestStringMatches(t *testing.T) {
+	ts := httptest.NewServer(setUpTestMux())
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		patterns       []string
+		expectedFields map[string]interface{}
+		expectedResult string
+	}{
+		{
+			name:     "all matching",
+			patterns: []string{`"service_status": "up"`, `"healthy" : "true"`},
+			expectedFields: map[string]interface{}{
+				"response_string_match":   1,
+				"response_string_match_0": 1,
+				"response_string_match_1": 1,
+				"result_type":             "success",
+				"result_code":             0,
+			},
+			expectedResult: "success",
+		},
+		{
+			name:     "one failing",
+			patterns: []string{`"service_status": "up"`, `"healthy" : "false"`},
+			expectedFields: map[string]interface{}{
+				"response_string_match":   0,
+				"response_string_match_0": 1,
+				"response_string_match_1": 0,
+				"result_type":             "response_string_mismatch",
+				"result_code":             1,
+			},
+			expectedResult: "response_string_mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:                   testutil.Logger{},
+				URLs:                  []string{ts.URL + "/jsonresponse"},
+				Method:                "GET",
+				ResponseStringMatches: tt.patterns,
+				ResponseTimeout:       config.Duration(time.Second * 20),
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Init())
+			require.NoError(t, h.Gather(&acc))
+
+			expectedTags := map[string]interface{}{
+				"server":      nil,
+				"method":      "GET",
+				"status_code": "200",
+				"result":      tt.expectedResult,
+			}
+			checkOutput(t, &acc, tt.expectedFields, expectedTags, nil, nil)
+		})
+	}
+}
+
+func TestStringMatchesInvalid(t *testing.T) {
+	h := &HTTPResponse{
+		Log:                   testutil.Logger{},
+		URLs:                  []string{"http://localhost"},
+		ResponseStringMatches: []string{"ok", "(invalid"},
+	}
+	require.ErrorContains(t, h.Init(), `failed to compile regular expression "(invalid"`)
+}
+
+func TestExpectedBodyFile(t *testing.T) {
+	ts := httptest.NewServer(setUpTestMux())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		content  string
+		compare  string
+		expected int
+	}{
+		{
+			name:     "exact match",
+			content:  `"service_status": "up", "healthy" : "true"`,
+			expected: 1,
+		},
+		{
+			name:     "exact mismatch on whitespace",
+			content:  "\"service_status\": \"up\",\n\"healthy\" : \"true\"\n",
+			expected: 0,
+		},
+		{
+			name:     "normalized match",
+			content:  "\"service_status\": \"up\",\n\"healthy\"   :\t\"true\"\n",
+			compare:  "normalized",
+			expected: 1,
+		},
+		{
+			name:     "differing content",
+			content:  `"service_status": "down", "healthy" : "false"`,
+			compare:  "normalized",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "expected.txt")
+			require.NoError(t, os.WriteFile(filename, []byte(tt.content), 0600))
+
+			h := &HTTPResponse{
+				Log:                 testutil.Logger{},
+				URLs:                []string{ts.URL + "/jsonresponse"},
+				Method:              "GET",
+				ResponseTimeout:     config.Duration(time.Second * 20),
+				ExpectedBodyFile:    filename,
+				ExpectedBodyCompare: tt.compare,
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Init())
+			require.NoError(t, h.Gather(&acc))
+
+			expectedFields := map[string]interface{}{
+				"http_response_code": http.StatusOK,
+				"body_exact_match":   tt.expected,
+				"result_type":        "success",
+				"result_code":        0,
+			}
+			expectedTags := map[string]interface{}{
+				"server":      nil,
+				"method":      "GET",
+				"status_code": "200",
+				"result":      "success",
+			}
+			checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
+		})
+	}
+}
+
+func TestExpectedBodyFileInvalid(t *testing.T) {
+	h := &HTTPResponse{
+		Log:              testutil.Logger{},
+		URLs:             []string{"http://localhost"},
+		ExpectedBodyFile: filepath.Join(t.TempDir(), "missing.txt"),
+	}
+	require.ErrorContains(t, h.Init(), "reading expected body file failed")
+
+	h = &HTTPResponse{
+		Log:                 testutil.Logger{},
+		URLs:                []string{"http://localhost"},
+		ExpectedBodyFile:    filepath.Join(t.TempDir(), "missing.txt"),
+		ExpectedBodyCompare: "fuzzy",
+	}
+	require.ErrorContains(t, h.Init(), `invalid expected_body_compare "fuzzy"`)
+}
+
 func TestStringMatchJson(t *testing.T) {
 	mux := setUpTestMux()
 	ts := httptest.NewServer(mux)
@@ -879,6 +1142,125 @@ func TestStringMatchJson(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
 }
 
+func TestResponseBodyRegex(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		regex    string
+		maxSize  config.Size
+		expected map[string]interface{}
+		absent   []string
+	}{
+		{
+			name:     "single group",
+			regex:    `"service_status": "(?P<service_status>[^"]*)"`,
+			expected: map[string]interface{}{"service_status": "up"},
+		},
+		{
+			name:     "first match only",
+			regex:    `"(?P<key>[a-z_]+)"\s*:\s*"(?P<value>[^"]*)"`,
+			expected: map[string]interface{}{"key": "service_status", "value": "up"},
+		},
+		{
+			name:     "group not participating",
+			regex:    `"service_status": "(?P<service_status>[^"]*)"(?P<missing>, "unknown")?`,
+			expected: map[string]interface{}{"service_status": "up"},
+			absent:   []string{"missing"},
+		},
+		{
+			name:   "no match",
+			regex:  `"version": "(?P<version>[^"]*)"`,
+			absent: []string{"version"},
+		},
+		{
+			name:     "body too large",
+			regex:    `"service_status": "(?P<service_status>[^"]*)"`,
+			maxSize:  config.Size(5),
+			expected: map[string]interface{}{"result_type": "body_read_error"},
+			absent:   []string{"service_status"},
+		},
+		{
+			name:     "match in truncated body",
+			regex:    `"service_status": "(?P<service_status>[^"]*)"`,
+			maxSize:  config.Size(25),
+			expected: map[string]interface{}{"result_type": "body_read_error"},
+			absent:   []string{"service_status"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:                 testutil.Logger{},
+				URLs:                []string{ts.URL + "/jsonresponse"},
+				ResponseTimeout:     config.Duration(time.Second * 20),
+				ResponseBodyRegex:   tt.regex,
+				ResponseBodyMaxSize: tt.maxSize,
+			}
+			require.NoError(t, h.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Gather(&acc))
+			checkFields(t, tt.expected, &acc)
+			checkAbsentFields(t, tt.absent, &acc)
+		})
+	}
+}
+
+func TestResponseBodyRegexInvalid(t *testing.T) {
+	h := &HTTPResponse{
+		Log:               testutil.Logger{},
+		ResponseBodyRegex: `"service_status": "([^"]*)"`,
+	}
+	require.ErrorContains(t, h.Init(), "does not contain a named capture group")
+
+	h = &HTTPResponse{
+		Log:               testutil.Logger{},
+		ResponseBodyRegex: `(?P<status>[a-z`,
+	}
+	require.ErrorContains(t, h.Init(), "failed to compile regular expression")
+}
+
+func TestResponseBodyRegexReservedField(t *testing.T) {
+	tests := []struct {
+		name      string
+		regex     string
+		bodyField string
+	}{
+		{
+			name:  "result code",
+			regex: `"status": (?P<result_code>\d+)`,
+		},
+		{
+			name:  "response time",
+			regex: `"took": (?P<response_time>\d+)`,
+		},
+		{
+			name:  "string match",
+			regex: `"status": "(?P<response_string_match_0>[^"]*)"`,
+		},
+		{
+			name:      "body field",
+			regex:     `"status": "(?P<body>[^"]*)"`,
+			bodyField: "body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:               testutil.Logger{},
+				ResponseBodyRegex: tt.regex,
+				ResponseBodyField: tt.bodyField,
+			}
+			require.ErrorContains(t, h.Init(), "conflicts with a field of the plugin")
+		})
+	}
+}
+
 func TestStringMatchFail(t *testing.T) {
 	mux := setUpTestMux()
 	ts := httptest.NewServer(mux)
@@ -1164,6 +1546,8 @@ func TestRedirect(t *testing.T) {
 	actual := acc.GetTelegrafMetrics()
 	for _, m := range actual {
 		m.RemoveField("response_time")
+		m.RemoveField("connect_time")
+		m.RemoveField("time_to_first_byte")
 	}
 
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
@@ -1280,6 +1664,83 @@ func TestStatusCodeMatch(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
 }
 
+func TestSuccessStatusRange(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		result     string
+		resultCode int
+	}{
+		{
+			name:       "in range",
+			path:       "/good",
+			statusCode: http.StatusOK,
+			result:     "success",
+			resultCode: 0,
+		},
+		{
+			name:       "single code",
+			path:       "/redirect",
+			statusCode: http.StatusMovedPermanently,
+			result:     "success",
+			resultCode: 0,
+		},
+		{
+			name:       "not found",
+			path:       "/notfound",
+			statusCode: http.StatusNotFound,
+			result:     "response_status_code_mismatch",
+			resultCode: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:                testutil.Logger{},
+				URLs:               []string{ts.URL + tt.path},
+				SuccessStatusRange: []string{"200-299", "301"},
+				ResponseTimeout:    config.Duration(time.Second * 20),
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Init())
+			require.NoError(t, h.Gather(&acc))
+
+			expectedFields := map[string]interface{}{
+				"http_response_code": tt.statusCode,
+				"result_type":        tt.result,
+				"result_code":        tt.resultCode,
+				"response_time":      nil,
+				"content_length":     nil,
+			}
+			expectedTags := map[string]interface{}{
+				"server":      nil,
+				"method":      http.MethodGet,
+				"status_code": strconv.Itoa(tt.statusCode),
+				"result":      tt.result,
+			}
+			checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
+		})
+	}
+}
+
+func TestSuccessStatusRangeInvalid(t *testing.T) {
+	for _, spec := range []string{"abc", "299-200", "200-"} {
+		h := &HTTPResponse{
+			Log:                testutil.Logger{},
+			URLs:               []string{"http://localhost"},
+			SuccessStatusRange: []string{spec},
+		}
+		require.ErrorContains(t, h.Init(), "invalid success_status_range", spec)
+	}
+}
+
 func TestStatusCodeAndStringMatch(t *testing.T) {
 	mux := setUpTestMux()
 	ts := httptest.NewServer(mux)
@@ -1393,6 +1854,298 @@ func TestSNI(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, absentFields, nil)
 }
 
+func TestTLSDetails(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	leaf := ts.Certificate()
+
+	h := &HTTPResponse{
+		Log:               testutil.Logger{},
+		URLs:              []string{ts.URL + "/good"},
+		Method:            "GET",
+		ResponseTimeout:   config.Duration(time.Second * 20),
+		CollectTLSDetails: true,
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	expectedFields := map[string]interface{}{
+		"http_response_code": http.StatusOK,
+		"result_type":        "success",
+		"result_code":        0,
+		"response_time":      nil,
+		"content_length":     nil,
+		"cert_chain_length":  1,
+	}
+	expectedTags := map[string]interface{}{
+		"server":      nil,
+		"method":      "GET",
+		"status_code": "200",
+		"result":      "success",
+		"issuer":      leaf.Issuer.String(),
+		"subject":     leaf.Subject.String(),
+	}
+	checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
+}
+
+func TestTLSDetailsDisabled(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/good"},
+		Method:          "GET",
+		ResponseTimeout: config.Duration(time.Second * 20),
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	checkAbsentFields(t, []string{"cert_chain_length"}, &acc)
+	checkAbsentTags(t, []string{"issuer", "subject"}, &acc)
+}
+
+func TestPhaseTimings(t *testing.T) {
+	ts := httptest.NewTLSServer(setUpTestMux())
+	defer ts.Close()
+
+	// Use a hostname to also trigger a DNS lookup
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	u.Host = net.JoinHostPort("localhost", u.Port())
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{u.String() + "/good"},
+		Method:          "GET",
+		ResponseTimeout: config.Duration(time.Second * 20),
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+	require.Len(t, acc.Metrics, 1)
+
+	fields := acc.Metrics[0].Fields
+	require.Equal(t, http.StatusOK, fields["http_response_code"])
+	for _, name := range []string{"dns_time", "connect_time", "tls_handshake_time", "time_to_first_byte"} {
+		require.Contains(t, fields, name)
+		require.IsType(t, float64(0), fields[name])
+		require.GreaterOrEqual(t, fields[name], float64(0), name)
+	}
+}
+
+func TestHTTP2(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/good"},
+		Method:          "GET",
+		ResponseTimeout: config.Duration(time.Second * 20),
+		HTTP2:           true,
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+			TLSMinVersion:      "TLS12",
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	expectedFields := map[string]interface{}{
+		"http_response_code": http.StatusOK,
+		"result_type":        "success",
+		"result_code":        0,
+		"response_time":      nil,
+		"content_length":     nil,
+	}
+	expectedTags := map[string]interface{}{
+		"server":       nil,
+		"method":       "GET",
+		"status_code":  "200",
+		"result":       "success",
+		"http_version": "HTTP/2.0",
+	}
+	checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &cryptotls.Config{MaxVersion: cryptotls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/good"},
+		Method:          "GET",
+		ResponseTimeout: config.Duration(time.Second * 20),
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+			TLSMinVersion:      "TLS13",
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Init())
+	require.NoError(t, h.Gather(&acc))
+
+	expectedFields := map[string]interface{}{
+		"result_type": "connection_failed",
+		"result_code": 3,
+	}
+	expectedTags := map[string]interface{}{
+		"server": nil,
+		"method": "GET",
+		"result": "connection_failed",
+	}
+	checkOutput(t, &acc, expectedFields, expectedTags, []string{"http_response_code"}, nil)
+}
+
+func TestIPVersion(t *testing.T) {
+	// Listen on all addresses to get a dual-stack server
+	listener, err := net.Listen("tcp", "[::]:0")
+	if err != nil {
+		t.Skipf("IPv6 not available: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Listener.Close()
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	// The test requires "localhost" to resolve to both address families
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	for _, network := range []string{"tcp4", "tcp6"} {
+		conn, err := net.Dial(network, net.JoinHostPort("localhost", port))
+		if err != nil {
+			t.Skipf("localhost not reachable via %s: %v", network, err)
+		}
+		conn.Close()
+	}
+
+	for _, version := range []string{"4", "6"} {
+		t.Run("IPv"+version, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:             testutil.Logger{},
+				URLs:            []string{"http://localhost:" + port + "/good"},
+				Method:          "GET",
+				ResponseTimeout: config.Duration(time.Second * 20),
+				IPVersion:       version,
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Init())
+			require.NoError(t, h.Gather(&acc))
+
+			expectedFields := map[string]interface{}{
+				"http_response_code": http.StatusOK,
+				"result_type":        "success",
+				"result_code":        0,
+				"response_time":      nil,
+				"content_length":     nil,
+			}
+			expectedTags := map[string]interface{}{
+				"server":      nil,
+				"method":      "GET",
+				"status_code": "200",
+				"result":      "success",
+				"ip_version":  version,
+			}
+			checkOutput(t, &acc, expectedFields, expectedTags, nil, nil)
+		})
+	}
+}
+
+func TestIPVersionInvalid(t *testing.T) {
+	h := &HTTPResponse{
+		Log:       testutil.Logger{},
+		URLs:      []string{"http://localhost"},
+		IPVersion: "5",
+	}
+	require.ErrorContains(t, h.Init(), "invalid ip_version")
+}
+
+func TestWarmup(t *testing.T) {
+	var requests, connections atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Delay the first request to be able to tell it from the timed one
+		if requests.Add(1) == 1 {
+			time.Sleep(time.Second)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL},
+		ResponseTimeout: config.Duration(time.Second * 20),
+		Warmup:          true,
+		ClientConfig:    tls.ClientConfig{InsecureSkipVerify: true},
+	}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Equal(t, int32(2), requests.Load())
+	require.Equal(t, int32(1), connections.Load())
+
+	require.Len(t, acc.Metrics, 1)
+	responseTime, ok := acc.Metrics[0].Fields["response_time"].(float64)
+	require.True(t, ok)
+	require.Less(t, responseTime, 1.0)
+
+	// The measured request must reuse the connection of the warmup request
+	require.NotContains(t, acc.Metrics[0].Fields, "connect_time")
+	require.NotContains(t, acc.Metrics[0].Fields, "tls_handshake_time")
+}
+
+func TestWarmupUnsafeMethod(t *testing.T) {
+	h := &HTTPResponse{
+		Log:    testutil.Logger{},
+		URLs:   []string{"http://localhost"},
+		Method: "POST",
+		Warmup: true,
+	}
+	require.ErrorContains(t, h.Init(), "warmup is not supported")
+}
+
 func Test_isURLInIPv6(t *testing.T) {
 	tests := []struct {
 		address url.URL
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"time"
@@ -67,6 +68,14 @@ type InfluxDBListener struct {
 	buffersCreated  selfstat.Stat
 	authFailures    selfstat.Stat
 
+	connectionsActive selfstat.Stat
+
+	bytesRecvRate    selfstat.Stat
+	writesServedRate selfstat.Stat
+	lastGather       time.Time
+	lastBytesRecv    int64
+	lastWritesServed int64
+
 	Log telegraf.Logger `toml:"-"`
 
 	mux http.ServeMux
@@ -76,7 +85,23 @@ func (*InfluxDBListener) SampleConfig() string {
 	return sampleConfig
 }
 
-func (*InfluxDBListener) Gather(telegraf.Accumulator) error {
+// Gather computes the per-second rates of the received bytes and served
+// writes since the last call
+func (h *InfluxDBListener) Gather(telegraf.Accumulator) error {
+	now := time.Now()
+	bytesRecv := h.bytesRecv.Get()
+	writesServed := h.writesServed.Get()
+
+	if !h.lastGather.IsZero() {
+		if elapsed := now.Sub(h.lastGather).Seconds(); elapsed > 0 {
+			h.bytesRecvRate.Set(int64(math.Round(float64(bytesRecv-h.lastBytesRecv) / elapsed)))
+			h.writesServedRate.Set(int64(math.Round(float64(writesServed-h.lastWritesServed) / elapsed)))
+		}
+	}
+
+	h.lastGather = now
+	h.lastBytesRecv = bytesRecv
+	h.lastWritesServed = writesServed
 	return nil
 }
 
@@ -101,6 +126,9 @@ func (h *InfluxDBListener) Init() error {
 	h.notFoundsServed = selfstat.Register("influxdb_listener", "not_founds_served", tags)
 	h.buffersCreated = selfstat.Register("influxdb_listener", "buffers_created", tags)
 	h.authFailures = selfstat.Register("influxdb_listener", "auth_failures", tags)
+	h.connectionsActive = selfstat.Register("influxdb_listener", "connections_active", tags)
+	h.bytesRecvRate = selfstat.Register("influxdb_listener", "bytes_received_per_second", tags)
+	h.writesServedRate = selfstat.Register("influxdb_listener", "writes_served_per_second", tags)
 	h.routes()
 
 	if h.MaxBodySize == 0 {
@@ -131,6 +159,7 @@ func (h *InfluxDBListener) Start(acc telegraf.Accumulator) error {
 		ReadTimeout:  time.Duration(h.ReadTimeout),
 		WriteTimeout: time.Duration(h.WriteTimeout),
 		TLSConfig:    tlsConf,
+		ConnState:    h.trackConnState,
 	}
 
 	var listener net.Listener
@@ -160,6 +189,16 @@ func (h *InfluxDBListener) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// trackConnState keeps the number of currently open client connections
+func (h *InfluxDBListener) trackConnState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		h.connectionsActive.Incr(1)
+	case http.StateClosed, http.StateHijacked:
+		h.connectionsActive.Incr(-1)
+	}
+}
+
 func (h *InfluxDBListener) Stop() {
 	err := h.server.Shutdown(context.Background())
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -898,3 +899,75 @@ func TestWriteParseErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestRateSelfstats(t *testing.T) {
+	// Use the upstream parser as it accounts the received bytes by the
+	// content-length of the request
+	listener := newTestListener()
+	listener.ParserType = "upstream"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	require.NoError(t, listener.Gather(acc))
+	start := time.Now()
+
+	// post a single message to listener
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 204, resp.StatusCode)
+	acc.Wait(1)
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, listener.Gather(acc))
+	elapsed := time.Since(start).Seconds()
+
+	// The rate must be at least the number of bytes received divided by the
+	// (upper bound of the) elapsed time, and at most ten times the number of
+	// bytes as at least 100ms passed.
+	rate := listener.bytesRecvRate.Get()
+	require.GreaterOrEqual(t, float64(rate), math.Floor(float64(len(testMsg))/elapsed))
+	require.LessOrEqual(t, rate, int64(10*len(testMsg)))
+	require.Positive(t, listener.writesServedRate.Get())
+}
+
+func TestConnectionsActiveSelfstat(t *testing.T) {
+	// Statistics are shared between listeners on the same address so use
+	// a dedicated one to not count the connections of other tests
+	listener := newTestListener()
+	listener.ServiceAddress = "127.0.0.1:0"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// Keep a request open by streaming the body slowly
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", reader)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	require.Eventually(t, func() bool {
+		return listener.connectionsActive.Get() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err := writer.Write([]byte(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	<-done
+
+	// Drop the idle keep-alive connection of the client
+	http.DefaultClient.CloseIdleConnections()
+	require.Eventually(t, func() bool {
+		return listener.connectionsActive.Get() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
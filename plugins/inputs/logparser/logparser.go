@@ -4,8 +4,14 @@
 package logparser
 
 import (
+	"bufio"
+	"compress/gzip"
 	_ "embed"
 	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
@@ -31,20 +37,30 @@ const (
 )
 
 type LogParser struct {
-	Files         []string        `toml:"files"`
-	FromBeginning bool            `toml:"from_beginning"`
-	WatchMethod   string          `toml:"watch_method"`
-	GrokConfig    grokConfig      `toml:"grok"`
-	Log           telegraf.Logger `toml:"-"`
-
-	tailers map[string]*tail.Tail
-	offsets map[string]int64
-	lines   chan logEntry
-	done    chan struct{}
-	wg      sync.WaitGroup
+	Files               []string          `toml:"files"`
+	FromBeginning       bool              `toml:"from_beginning"`
+	WatchMethod         string            `toml:"watch_method"`
+	PathTagPatterns     map[string]string `toml:"path_tag_patterns"`
+	MeasurementTemplate string            `toml:"measurement_template"`
+	ReadCompressed      bool              `toml:"read_compressed"`
+	FileMeasurements    map[string]string `toml:"file_measurements"`
+	GrokConfig          grokConfig        `toml:"grok"`
+	Log                 telegraf.Logger   `toml:"-"`
+
+	tailers  map[string]*tail.Tail
+	offsets  map[string]int64
+	lines    chan logEntry
+	done     chan struct{}
+	wg       sync.WaitGroup
+	pathTags map[string]*regexp.Regexp
+	fileMeas []fileMeasurement
 
 	acc telegraf.Accumulator
 
+	// number of unparseable lines per file since the last gather
+	parseErrors   map[string]int64
+	parseErrorsMu sync.Mutex
+
 	sync.Mutex
 	grokParser telegraf.Parser
 }
@@ -59,6 +75,12 @@ type grokConfig struct {
 	UniqueTimestamp    string
 }
 
+// fileMeasurement is the measurement name for files matching the glob
+type fileMeasurement struct {
+	glob *globpath.GlobPath
+	name string
+}
+
 type logEntry struct {
 	path string
 	line string
@@ -81,6 +103,25 @@ func (l *LogParser) Start(acc telegraf.Accumulator) error {
 	l.lines = make(chan logEntry, 1000)
 	l.done = make(chan struct{})
 	l.tailers = make(map[string]*tail.Tail)
+	l.parseErrors = make(map[string]int64)
+
+	l.pathTags = make(map[string]*regexp.Regexp, len(l.PathTagPatterns))
+	for tag, pattern := range l.PathTagPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling path tag pattern for %q failed: %w", tag, err)
+		}
+		l.pathTags[tag] = re
+	}
+
+	l.fileMeas = make([]fileMeasurement, 0, len(l.FileMeasurements))
+	for _, pattern := range slices.Sorted(maps.Keys(l.FileMeasurements)) {
+		g, err := globpath.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling file measurement glob %q failed: %w", pattern, err)
+		}
+		l.fileMeas = append(l.fileMeas, fileMeasurement{glob: g, name: l.FileMeasurements[pattern]})
+	}
 
 	mName := "logparser"
 	if l.GrokConfig.MeasurementName != "" {
@@ -119,13 +160,21 @@ func (l *LogParser) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (l *LogParser) Gather(_ telegraf.Accumulator) error {
+func (l *LogParser) Gather(acc telegraf.Accumulator) error {
 	l.Lock()
 	defer l.Unlock()
 
 	// always start from the beginning of files that appear while we're running
 	l.tailNewFiles(true)
 
+	// report the files with unparseable lines since the last gather
+	l.parseErrorsMu.Lock()
+	for file, count := range l.parseErrors {
+		acc.AddFields("logparser_errors", map[string]interface{}{"parse_errors": count}, map[string]string{"path": file})
+	}
+	clear(l.parseErrors)
+	l.parseErrorsMu.Unlock()
+
 	return nil
 }
 
@@ -134,6 +183,10 @@ func (l *LogParser) Stop() {
 	defer l.Unlock()
 
 	for _, t := range l.tailers {
+		if t == nil {
+			// compressed file read once, nothing to stop
+			continue
+		}
 		if !l.FromBeginning {
 			// store offset for resume
 			offset, err := t.Tell()
@@ -187,6 +240,16 @@ func (l *LogParser) tailNewFiles(fromBeginning bool) {
 				continue
 			}
 
+			if l.ReadCompressed && strings.HasSuffix(file, ".gz") {
+				// read compressed archives once and remember them using a
+				// nil tailer to not read them again
+				l.Log.Debugf("Reading compressed file: %v", file)
+				l.wg.Add(1)
+				go l.readCompressed(file)
+				l.tailers[file] = nil
+				continue
+			}
+
 			var seek *tail.SeekInfo
 			if !fromBeginning {
 				if offset, ok := l.offsets[file]; ok {
@@ -255,6 +318,43 @@ func (l *LogParser) receiver(tailer *tail.Tail) {
 	}
 }
 
+// readCompressed is launched as a goroutine to read a gzip compressed file
+// once and send its log lines down the l.lines channel.
+func (l *LogParser) readCompressed(filename string) {
+	defer l.wg.Done()
+
+	f, err := os.Open(filename)
+	if err != nil {
+		l.acc.AddError(err)
+		return
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		l.acc.AddError(fmt.Errorf("reading compressed file %s failed: %w", filename, err))
+		return
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		entry := logEntry{
+			path: filename,
+			line: strings.TrimRight(scanner.Text(), "\r"),
+		}
+
+		select {
+		case <-l.done:
+			return
+		case l.lines <- entry:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		l.acc.AddError(fmt.Errorf("reading compressed file %s failed: %w", filename, err))
+	}
+}
+
 // parse is launched as a goroutine to watch the l.lines channel.
 // when a line is available, parse parses it and adds the metric(s) to the
 // accumulator.
@@ -274,16 +374,78 @@ func (l *LogParser) parser() {
 			}
 		}
 		m, err = l.grokParser.ParseLine(entry.line)
-		if err == nil {
-			if m != nil {
-				tags := m.Tags()
-				tags["path"] = entry.path
-				l.acc.AddFields(m.Name(), m.Fields(), tags, m.Time())
-			}
-		} else {
+		if err != nil {
 			l.Log.Errorf("Error parsing log line: %s", err.Error())
 		}
+		if m == nil {
+			// count lines failing to parse or not matching any pattern
+			l.parseErrorsMu.Lock()
+			l.parseErrors[entry.path]++
+			l.parseErrorsMu.Unlock()
+			continue
+		}
+
+		pathTags := l.extractPathTags(entry.path)
+		tags := m.Tags()
+		tags["path"] = entry.path
+		for k, v := range pathTags {
+			tags[k] = v
+		}
+		name := l.fileMeasurementName(entry.path, m.Name())
+		l.acc.AddFields(l.measurementName(name, pathTags), m.Fields(), tags, m.Time())
+	}
+}
+
+// extractPathTags extracts tags from the file path using the configured
+// patterns. The first capturing group is used as tag value if present,
+// otherwise the whole match is used.
+func (l *LogParser) extractPathTags(path string) map[string]string {
+	tags := make(map[string]string, len(l.pathTags))
+	for tag, re := range l.pathTags {
+		match := re.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+		if len(match) > 1 {
+			tags[tag] = match[1]
+		} else {
+			tags[tag] = match[0]
+		}
+	}
+	return tags
+}
+
+// fileMeasurementName returns the measurement name configured for the first
+// glob, in lexical order, matching the path or the given name otherwise.
+func (l *LogParser) fileMeasurementName(path, name string) string {
+	for _, fm := range l.fileMeas {
+		if fm.glob.MatchString(path) {
+			return fm.name
+		}
+	}
+	return name
+}
+
+// measurementName expands the measurement template using the tags extracted
+// from the file path. The given static name is used if no template is
+// configured or if the template references tags not extracted for the path.
+func (l *LogParser) measurementName(name string, pathTags map[string]string) string {
+	if l.MeasurementTemplate == "" {
+		return name
+	}
+
+	complete := true
+	expanded := os.Expand(l.MeasurementTemplate, func(key string) string {
+		v, found := pathTags[key]
+		if !found {
+			complete = false
+		}
+		return v
+	})
+	if !complete {
+		return name
 	}
+	return expanded
 }
 
 func newLogParser() *LogParser {
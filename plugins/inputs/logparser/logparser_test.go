@@ -228,6 +228,259 @@ func TestGrokParseLogFiles_TimestampInEpochMilli(t *testing.T) {
 		})
 }
 
+func TestGrokParseLogFilesPathTags(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs", "web-01.example.com")
+	require.NoError(t, os.MkdirAll(dir, 0750))
+
+	input, err := os.ReadFile(filepath.Join(testdataDir, "test_a.log"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.log"), input, 0640))
+
+	logparser := &LogParser{
+		Log:           testutil.Logger{},
+		FromBeginning: true,
+		Files:         []string{filepath.Join(dir, "app.log")},
+		PathTagPatterns: map[string]string{
+			"host":   `[/\\]logs[/\\](?P<host>[^/\\]+)[/\\]`,
+			"absent": `[/\\]nonexistent[/\\]`,
+		},
+		GrokConfig: grokConfig{
+			MeasurementName:    "logparser_grok",
+			Patterns:           []string{"%{TEST_LOG_A}"},
+			CustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+	acc.Wait(1)
+
+	logparser.Stop()
+
+	acc.AssertContainsTaggedFields(t, "logparser_grok",
+		map[string]interface{}{
+			"clientip":      "192.168.1.1",
+			"myfloat":       float64(1.25),
+			"response_time": int64(5432),
+			"myint":         int64(101),
+		},
+		map[string]string{
+			"response_code": "200",
+			"path":          filepath.Join(dir, "app.log"),
+			"host":          "web-01.example.com",
+		})
+}
+
+func TestGrokParseLogFilesPathTagsEnvironment(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app-prod")
+	require.NoError(t, os.MkdirAll(dir, 0750))
+
+	input, err := os.ReadFile(filepath.Join(testdataDir, "test_a.log"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "access.log"), input, 0640))
+
+	logparser := &LogParser{
+		Log:           testutil.Logger{},
+		FromBeginning: true,
+		Files:         []string{filepath.Join(dir, "access.log")},
+		PathTagPatterns: map[string]string{
+			"env":  `app-([a-z]+)[/\\]`,
+			"team": `team-([a-z]+)[/\\]`,
+		},
+		GrokConfig: grokConfig{
+			MeasurementName:    "logparser_grok",
+			Patterns:           []string{"%{TEST_LOG_A}"},
+			CustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+	acc.Wait(1)
+
+	logparser.Stop()
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, "prod", metrics[0].Tags()["env"])
+	require.False(t, metrics[0].HasTag("team"))
+}
+
+func TestGrokParseLogFilesMeasurementTemplate(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join(testdataDir, "test_a.log"))
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	for _, dir := range []string{"logs/web", "logs/db", "other"} {
+		dir = filepath.Join(root, filepath.FromSlash(dir))
+		require.NoError(t, os.MkdirAll(dir, 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app.log"), input, 0640))
+	}
+
+	logparser := &LogParser{
+		Log:           testutil.Logger{},
+		FromBeginning: true,
+		Files: []string{
+			filepath.Join(root, "logs", "web", "app.log"),
+			filepath.Join(root, "logs", "db", "app.log"),
+			filepath.Join(root, "other", "app.log"),
+		},
+		PathTagPatterns:     map[string]string{"service": `[/\\]logs[/\\]([^/\\]+)[/\\]`},
+		MeasurementTemplate: "app_${service}",
+		GrokConfig: grokConfig{
+			MeasurementName:    "logparser_grok",
+			Patterns:           []string{"%{TEST_LOG_A}"},
+			CustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+	acc.Wait(3)
+
+	logparser.Stop()
+
+	names := make([]string, 0, len(acc.Metrics))
+	for _, m := range acc.GetTelegrafMetrics() {
+		names = append(names, m.Name())
+	}
+	require.ElementsMatch(t, []string{"app_web", "app_db", "logparser_grok"}, names)
+	require.Equal(t, "web", acc.TagValue("app_web", "service"))
+	require.Equal(t, "db", acc.TagValue("app_db", "service"))
+}
+
+func TestGrokParseCompressedLogFiles(t *testing.T) {
+	filename := filepath.Join(testdataDir, "compressed", "test_a.log.gz")
+	logparser := &LogParser{
+		Log:            testutil.Logger{},
+		FromBeginning:  true,
+		ReadCompressed: true,
+		Files:          []string{filepath.Join(testdataDir, "compressed", "*.gz")},
+		GrokConfig: grokConfig{
+			MeasurementName:    "logparser_grok",
+			Patterns:           []string{"%{TEST_LOG_A}"},
+			CustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+	acc.Wait(1)
+
+	// The archive must not be read again
+	require.NoError(t, logparser.Gather(&acc))
+	require.Contains(t, logparser.tailers, filename)
+	require.Nil(t, logparser.tailers[filename])
+
+	logparser.Stop()
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"logparser_grok",
+			map[string]string{
+				"response_code": "200",
+				"path":          filename,
+			},
+			map[string]interface{}{
+				"clientip":      "192.168.1.1",
+				"myfloat":       float64(1.25),
+				"response_time": int64(5432),
+				"myint":         int64(101),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Empty(t, acc.Errors)
+}
+
+func TestGrokParseLogFilesFileMeasurements(t *testing.T) {
+	logparser := &LogParser{
+		Log:           testutil.Logger{},
+		FromBeginning: true,
+		Files:         []string{filepath.Join(testdataDir, "*.log")},
+		FileMeasurements: map[string]string{
+			filepath.Join(testdataDir, "*_a.log"): "access_log",
+			filepath.Join(testdataDir, "*_b.log"): "app_log",
+		},
+		GrokConfig: grokConfig{
+			MeasurementName:    "logparser_grok",
+			Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_B}", "%{TEST_LOG_C}"},
+			CustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+	acc.Wait(3)
+
+	logparser.Stop()
+
+	names := make(map[string]string)
+	for _, m := range acc.GetTelegrafMetrics() {
+		path, found := m.GetTag("path")
+		require.True(t, found)
+		names[filepath.Base(path)] = m.Name()
+	}
+	require.Equal(t, map[string]string{
+		"test_a.log": "access_log",
+		"test_b.log": "app_log",
+		"test_c.log": "logparser_grok",
+	}, names)
+}
+
+func TestGrokParseLogFilesParseErrors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	input, err := os.ReadFile(filepath.Join(testdataDir, "test_a.log"))
+	require.NoError(t, err)
+	input = append([]byte("this line does not match\n"), input...)
+	require.NoError(t, os.WriteFile(filename, input, 0640))
+
+	logparser := &LogParser{
+		Log:           testutil.Logger{},
+		FromBeginning: true,
+		Files:         []string{filename},
+		GrokConfig: grokConfig{
+			MeasurementName:    "logparser_grok",
+			Patterns:           []string{"%{TEST_LOG_A}"},
+			CustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+	defer logparser.Stop()
+	acc.Wait(1)
+
+	// Lines are parsed in order, so the error is recorded at this point
+	var errAcc testutil.Accumulator
+	require.NoError(t, logparser.Gather(&errAcc))
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"logparser_errors",
+			map[string]string{"path": filename},
+			map[string]interface{}{"parse_errors": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, errAcc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The counter is reset after each gather
+	errAcc.ClearMetrics()
+	require.NoError(t, logparser.Gather(&errAcc))
+	require.Empty(t, errAcc.GetTelegrafMetrics())
+}
+
+func TestPathTagPatternsInvalid(t *testing.T) {
+	logparser := &LogParser{
+		Log:             testutil.Logger{},
+		PathTagPatterns: map[string]string{"host": "(unclosed"},
+	}
+
+	acc := testutil.Accumulator{}
+	require.ErrorContains(t, logparser.Start(&acc), `compiling path tag pattern for "host" failed`)
+}
+
 func getTestdataDir() string {
 	dir, err := os.Getwd()
 	if err != nil {
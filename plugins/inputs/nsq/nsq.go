@@ -31,10 +31,12 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -47,7 +49,8 @@ const (
 )
 
 type NSQ struct {
-	Endpoints []string `toml:"endpoints"`
+	Endpoints []string      `toml:"endpoints"`
+	Token     config.Secret `toml:"token"`
 
 	tls.ClientConfig
 	httpClient *http.Client
@@ -100,7 +103,21 @@ func (n *NSQ) gatherEndpoint(e string, acc telegraf.Accumulator) error {
 	if err != nil {
 		return err
 	}
-	r, err := n.httpClient.Get(u.String())
+	request, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	if !n.Token.Empty() {
+		token, err := n.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		bearer := "Bearer " + strings.TrimSpace(token.String())
+		token.Destroy()
+		request.Header.Set("Authorization", bearer)
+	}
+
+	r, err := n.httpClient.Do(request)
 	if err != nil {
 		return fmt.Errorf("error while polling %s: %w", u.String(), err)
 	}
@@ -173,6 +190,7 @@ func gatherTopicStats(t topicStats, acc telegraf.Accumulator, host, version stri
 		"backend_depth": t.BackendDepth,
 		"message_count": t.MessageCount,
 		"channel_count": int64(len(t.Channels)),
+		"paused":        boolToInt(t.Paused),
 	}
 	acc.AddFields("nsq_topic", fields, tags)
 
@@ -198,6 +216,7 @@ func gatherChannelStats(c channelStats, acc telegraf.Accumulator, host, version,
 		"requeue_count":  c.RequeueCount,
 		"timeout_count":  c.TimeoutCount,
 		"client_count":   int64(len(c.Clients)),
+		"paused":         boolToInt(c.Paused),
 	}
 
 	acc.AddFields("nsq_channel", fields, tags)
@@ -235,6 +254,13 @@ func gatherClientStats(c clientStats, acc telegraf.Accumulator, host, version, t
 	acc.AddFields("nsq_client", fields, tags)
 }
 
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 type nsqStats struct {
 	Code int64        `json:"status_code"`
 	Txt  string       `json:"status_txt"`
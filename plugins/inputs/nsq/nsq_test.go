@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -58,6 +59,7 @@ func TestNSQStatsV1(t *testing.T) {
 				"backend_depth": int64(13),
 				"message_count": int64(14),
 				"channel_count": int64(1),
+				"paused":        int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -75,6 +77,7 @@ func TestNSQStatsV1(t *testing.T) {
 				"requeue_count":  int64(5),
 				"timeout_count":  int64(6),
 				"client_count":   int64(1),
+				"paused":         int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -107,6 +110,7 @@ func TestNSQStatsV1(t *testing.T) {
 				"backend_depth": int64(29),
 				"message_count": int64(30),
 				"channel_count": int64(1),
+				"paused":        int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -124,6 +128,7 @@ func TestNSQStatsV1(t *testing.T) {
 				"requeue_count":  int64(20),
 				"timeout_count":  int64(21),
 				"client_count":   int64(1),
+				"paused":         int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -155,6 +160,42 @@ func TestNSQStatsV1(t *testing.T) {
 	}
 }
 
+func TestNSQStatsTLSToken(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, err := fmt.Fprintln(w, responseV1); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	// Requests without the token are rejected
+	n := newNSQ()
+	n.Endpoints = []string{ts.URL}
+	n.InsecureSkipVerify = true
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, acc.GatherError(n.Gather), "401 Unauthorized")
+
+	// Requests with the token succeed
+	n = newNSQ()
+	n.Endpoints = []string{ts.URL}
+	n.InsecureSkipVerify = true
+	n.Token = config.NewSecret([]byte("secret-token"))
+
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(n.Gather))
+	require.True(t, acc.HasMeasurement("nsq_server"))
+	require.True(t, acc.HasMeasurement("nsq_topic"))
+	require.True(t, acc.HasMeasurement("nsq_channel"))
+	require.True(t, acc.HasMeasurement("nsq_client"))
+}
+
 // v1 version of localhost/stats?format=json response body
 var responseV1 = `
 {
@@ -318,6 +359,7 @@ func TestNSQStatsPreV1(t *testing.T) {
 				"backend_depth": int64(13),
 				"message_count": int64(14),
 				"channel_count": int64(1),
+				"paused":        int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -335,6 +377,7 @@ func TestNSQStatsPreV1(t *testing.T) {
 				"requeue_count":  int64(5),
 				"timeout_count":  int64(6),
 				"client_count":   int64(1),
+				"paused":         int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -367,6 +410,7 @@ func TestNSQStatsPreV1(t *testing.T) {
 				"backend_depth": int64(29),
 				"message_count": int64(30),
 				"channel_count": int64(1),
+				"paused":        int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
@@ -384,6 +428,7 @@ func TestNSQStatsPreV1(t *testing.T) {
 				"requeue_count":  int64(20),
 				"timeout_count":  int64(21),
 				"client_count":   int64(1),
+				"paused":         int64(0),
 			},
 			map[string]string{
 				"server_host":    host,
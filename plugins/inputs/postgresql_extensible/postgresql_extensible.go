@@ -3,7 +3,10 @@ package postgresql_extensible
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +16,7 @@ import (
 	_ "github.com/jackc/pgx/v4/stdlib"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/postgresql"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -26,23 +30,32 @@ var ignoredColumns = map[string]bool{"stats_reset": true}
 type Postgresql struct {
 	Databases          []string        `deprecated:"1.22.4;use the sqlquery option to specify database to use"`
 	Query              []query         `toml:"query"`
+	Presets            []string        `toml:"presets"`
 	PreparedStatements bool            `toml:"prepared_statements"`
+	Timeout            config.Duration `toml:"timeout"`
 	Log                telegraf.Logger `toml:"-"`
 	postgresql.Config
 
 	service *postgresql.Service
+
+	// prepared statements keyed by the query text for the database handle
+	// the statements were prepared on
+	stmts   map[string]*sql.Stmt
+	stmtsDB *sql.DB
 }
 
 type query struct {
-	Sqlquery    string `toml:"sqlquery"`
-	Script      string `toml:"script"`
-	Version     int    `deprecated:"1.28.0;use minVersion to specify minimal DB version this query supports"`
-	MinVersion  int    `toml:"min_version"`
-	MaxVersion  int    `toml:"max_version"`
-	Withdbname  bool   `deprecated:"1.22.4;use the sqlquery option to specify database to use"`
-	Tagvalue    string `toml:"tagvalue"`
-	Measurement string `toml:"measurement"`
-	Timestamp   string `toml:"timestamp"`
+	Sqlquery        string `toml:"sqlquery"`
+	Script          string `toml:"script"`
+	Version         int    `deprecated:"1.28.0;use minVersion to specify minimal DB version this query supports"`
+	MinVersion      int    `toml:"min_version"`
+	MaxVersion      int    `toml:"max_version"`
+	Withdbname      bool   `deprecated:"1.22.4;use the sqlquery option to specify database to use"`
+	Tagvalue        string `toml:"tagvalue"`
+	Measurement     string `toml:"measurement"`
+	Timestamp       string `toml:"timestamp"`
+	TimestampFormat string `toml:"timestamp_format"`
+	FieldPrefix     string `toml:"field_prefix"`
 
 	additionalTags map[string]bool
 }
@@ -56,6 +69,15 @@ func (*Postgresql) SampleConfig() string {
 }
 
 func (p *Postgresql) Init() error {
+	// Add the queries of the selected presets
+	for _, name := range p.Presets {
+		queries, found := presets[name]
+		if !found {
+			return fmt.Errorf("unknown preset %q", name)
+		}
+		p.Query = append(p.Query, queries...)
+	}
+
 	// Set defaults for the queries
 	for i, q := range p.Query {
 		if q.Sqlquery == "" {
@@ -110,7 +132,11 @@ func (p *Postgresql) Gather(acc telegraf.Accumulator) error {
 	// Retrieving the database version
 	query := `SELECT setting::integer / 100 AS version FROM pg_settings WHERE name = 'server_version_num'`
 	var dbVersion int
-	if err := p.service.DB.QueryRow(query).Scan(&dbVersion); err != nil {
+	ctx, cancel := p.queryContext()
+	err := p.service.DB.QueryRowContext(ctx, query).Scan(&dbVersion)
+	cancel()
+	if err != nil {
+		p.Log.Debugf("Querying database version failed: %v", p.service.SanitizeError(err))
 		dbVersion = 0
 	}
 
@@ -129,13 +155,82 @@ func (p *Postgresql) Gather(acc telegraf.Accumulator) error {
 }
 
 func (p *Postgresql) Stop() {
+	p.closeStatements()
 	p.service.Stop()
 }
 
+// statement returns the prepared statement for the given query, preparing
+// the query on first use. The cache is reset if the database handle changed.
+func (p *Postgresql) statement(ctx context.Context, sqlquery string) (*sql.Stmt, error) {
+	if p.stmts == nil || p.stmtsDB != p.service.DB {
+		p.closeStatements()
+		p.stmts = make(map[string]*sql.Stmt, len(p.Query))
+		p.stmtsDB = p.service.DB
+	}
+
+	if stmt, found := p.stmts[sqlquery]; found {
+		return stmt, nil
+	}
+
+	stmt, err := p.service.DB.PrepareContext(ctx, sqlquery)
+	if err != nil {
+		return nil, err
+	}
+	p.stmts[sqlquery] = stmt
+	return stmt, nil
+}
+
+// invalidateStatement closes and removes the statement of the given query
+// from the cache to re-prepare it on next use
+func (p *Postgresql) invalidateStatement(sqlquery string) {
+	if stmt, found := p.stmts[sqlquery]; found {
+		stmt.Close()
+		delete(p.stmts, sqlquery)
+	}
+}
+
+func (p *Postgresql) closeStatements() {
+	for _, stmt := range p.stmts {
+		stmt.Close()
+	}
+	p.stmts = nil
+	p.stmtsDB = nil
+}
+
+// runQuery executes the query using a cached prepared statement if prepared
+// statements are enabled and directly otherwise
+func (p *Postgresql) runQuery(ctx context.Context, sqlquery string) (*sql.Rows, error) {
+	if !p.PreparedStatements {
+		return p.service.DB.QueryContext(ctx, sqlquery)
+	}
+
+	stmt, err := p.statement(ctx, sqlquery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		p.invalidateStatement(sqlquery)
+		return nil, err
+	}
+	return rows, nil
+}
+
+// queryContext returns a context limited by the configured timeout, if any
+func (p *Postgresql) queryContext() (context.Context, context.CancelFunc) {
+	if p.Timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), time.Duration(p.Timeout))
+}
+
 func (p *Postgresql) gatherMetricsFromQuery(acc telegraf.Accumulator, q query, timestamp time.Time) error {
-	rows, err := p.service.DB.Query(q.Sqlquery)
+	ctx, cancel := p.queryContext()
+	defer cancel()
+
+	rows, err := p.runQuery(ctx, q.Sqlquery)
 	if err != nil {
-		return err
+		return p.queryError(q, err)
 	}
 
 	defer rows.Close()
@@ -151,7 +246,16 @@ func (p *Postgresql) gatherMetricsFromQuery(acc telegraf.Accumulator, q query, t
 			return err
 		}
 	}
-	return nil
+	return p.queryError(q, rows.Err())
+}
+
+// queryError prepares the error of a query for reporting, i.e. removes
+// sensitive connection information from the message
+func (p *Postgresql) queryError(q query, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("query for measurement %q timed out after %s: %w", q.Measurement, time.Duration(p.Timeout), err)
+	}
+	return p.service.SanitizeError(err)
 }
 
 func (p *Postgresql) accRow(acc telegraf.Accumulator, row scanner, columns []string, q query, timestamp time.Time) error {
@@ -195,18 +299,24 @@ func (p *Postgresql) accRow(acc telegraf.Accumulator, row scanner, columns []str
 	fields := make(map[string]interface{})
 	for col, val := range columnMap {
 		p.Log.Debugf("Column: %s = %T: %v\n", col, *val, *val)
-		_, ignore := ignoredColumns[col]
-		if ignore || *val == nil {
+		if *val == nil {
 			continue
 		}
 
+		// The timestamp column may be one of the otherwise ignored columns
 		if col == q.Timestamp {
-			if v, ok := (*val).(time.Time); ok {
+			if v, err := parseTimestamp(*val, q.TimestampFormat); err != nil {
+				p.Log.Errorf("Parsing timestamp column %q failed, using current time: %v", col, err)
+			} else {
 				timestamp = v
 			}
 			continue
 		}
 
+		if ignoredColumns[col] {
+			continue
+		}
+
 		if q.additionalTags[col] {
 			v, err := internal.ToString(*val)
 			if err != nil {
@@ -218,15 +328,32 @@ func (p *Postgresql) accRow(acc telegraf.Accumulator, row scanner, columns []str
 		}
 
 		if v, ok := (*val).([]byte); ok {
-			fields[col] = string(v)
+			fields[q.FieldPrefix+col] = string(v)
 		} else {
-			fields[col] = *val
+			fields[q.FieldPrefix+col] = *val
 		}
 	}
 	acc.AddFields(q.Measurement, fields, tags, timestamp)
 	return nil
 }
 
+// parseTimestamp converts the value of a timestamp column to time. Native
+// timestamps are used as-is, other values are parsed using the given format
+// defaulting to RFC3339.
+func parseTimestamp(value interface{}, format string) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		value = string(v)
+	}
+
+	if format == "" {
+		format = time.RFC3339
+	}
+	return internal.ParseTimestamp(format, value, nil)
+}
+
 func init() {
 	inputs.Add("postgresql_extensible", func() telegraf.Input {
 		return &Postgresql{
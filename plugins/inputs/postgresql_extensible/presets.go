@@ -0,0 +1,50 @@
+package postgresql_extensible
+
+// presets contains well-known queries for common monitoring tasks usable
+// without writing custom SQL
+var presets = map[string][]query{
+	"bgwriter": {
+		{
+			Measurement: "postgresql_bgwriter",
+			Sqlquery:    "SELECT * FROM pg_stat_bgwriter",
+			MinVersion:  901,
+		},
+	},
+	"locks": {
+		{
+			Measurement: "postgresql_locks",
+			Sqlquery: `SELECT d.datname, l.mode, count(*) AS locks_count
+FROM pg_locks l JOIN pg_database d ON d.oid = l.database
+GROUP BY d.datname, l.mode`,
+			Tagvalue:   "mode",
+			MinVersion: 901,
+		},
+	},
+	"replication": {
+		{
+			Measurement: "postgresql_replication",
+			Sqlquery: `SELECT application_name, client_addr::text AS client_addr, state,
+pg_xlog_location_diff(pg_current_xlog_location(), replay_location) AS replay_lag_bytes
+FROM pg_stat_replication`,
+			Tagvalue:   "application_name,client_addr,state",
+			MinVersion: 901,
+			MaxVersion: 1000,
+		},
+		{
+			Measurement: "postgresql_replication",
+			Sqlquery: `SELECT application_name, client_addr::text AS client_addr, state,
+pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn) AS replay_lag_bytes,
+EXTRACT(EPOCH FROM replay_lag)::float8 AS replay_lag_seconds
+FROM pg_stat_replication`,
+			Tagvalue:   "application_name,client_addr,state",
+			MinVersion: 1000,
+		},
+		{
+			Measurement: "postgresql_replication_standby",
+			Sqlquery: `SELECT CASE WHEN pg_is_in_recovery()
+THEN COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)::float8
+ELSE 0 END AS replay_delay_seconds`,
+			MinVersion: 901,
+		},
+	},
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -93,6 +94,47 @@ func (*NativeFinder) children(processID pid) ([]pid, error) {
 	return pids, err
 }
 
+// Environ matches on the environment variables of the process
+func (*NativeFinder) environ(key string, value *regexp.Regexp) ([]pid, error) {
+	procs, err := fastProcessList()
+	if err != nil {
+		return nil, err
+	}
+	providers := make(map[pid]environProvider, len(procs))
+	for _, p := range procs {
+		providers[pid(p.Pid)] = p
+	}
+	return matchEnviron(providers, key, value), nil
+}
+
+// environProvider gives access to the environment variables of a process
+type environProvider interface {
+	Environ() ([]string, error)
+}
+
+// matchEnviron returns the sorted PIDs of the processes having the given
+// environment variable set with a value matching the expression. A nil
+// expression matches any value.
+func matchEnviron(procs map[pid]environProvider, key string, value *regexp.Regexp) []pid {
+	pids := make([]pid, 0)
+	for id, p := range procs {
+		environ, err := p.Environ()
+		if err != nil {
+			// skip, this can be caused by the pid no longer exists, or you don't have permissions to access it
+			continue
+		}
+		for _, entry := range environ {
+			k, v, found := strings.Cut(entry, "=")
+			if found && k == key && (value == nil || value.MatchString(v)) {
+				pids = append(pids, id)
+				break
+			}
+		}
+	}
+	slices.Sort(pids)
+	return pids
+}
+
 func fastProcessList() ([]*gopsprocess.Process, error) {
 	pids, err := gopsprocess.Pids()
 	if err != nil {
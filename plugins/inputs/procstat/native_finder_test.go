@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"os/user"
+	"regexp"
 	"runtime"
 	"testing"
 
@@ -96,3 +97,31 @@ func TestGather_RealUserIntegration(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, pids)
 }
+
+type fakeEnvironProcess struct {
+	env map[string]string
+	err error
+}
+
+func (p *fakeEnvironProcess) Environ() ([]string, error) {
+	environ := make([]string, 0, len(p.env))
+	for k, v := range p.env {
+		environ = append(environ, k+"="+v)
+	}
+	return environ, p.err
+}
+
+func TestMatchEnviron(t *testing.T) {
+	procs := map[pid]environProvider{
+		1: &fakeEnvironProcess{env: map[string]string{"APP_ROLE": "worker", "HOME": "/root"}},
+		2: &fakeEnvironProcess{env: map[string]string{"APP_ROLE": "scheduler"}},
+		3: &fakeEnvironProcess{env: map[string]string{"APP_ROLE": "worker-2", "PATH": "/bin"}},
+		4: &fakeEnvironProcess{env: map[string]string{"APP": "worker"}},
+		5: &fakeEnvironProcess{err: os.ErrPermission},
+	}
+
+	require.Equal(t, []pid{1, 2, 3}, matchEnviron(procs, "APP_ROLE", nil))
+	require.Equal(t, []pid{1, 3}, matchEnviron(procs, "APP_ROLE", regexp.MustCompile("^worker")))
+	require.Equal(t, []pid{1}, matchEnviron(procs, "APP_ROLE", regexp.MustCompile("^worker$")))
+	require.Empty(t, matchEnviron(procs, "UNKNOWN", nil))
+}
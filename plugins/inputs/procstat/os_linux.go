@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
@@ -87,6 +89,16 @@ func findByWindowsServices(_ []string) ([]processGroup, error) {
 	return nil, nil
 }
 
+func containerID(proc process, pattern *regexp.Regexp) (string, error) {
+	f, err := os.Open(filepath.Join(internal.GetProcPath(), strconv.Itoa(int(proc.pid())), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return extractContainerID(f, pattern)
+}
+
 func collectTotalReadWrite(proc process) (r, w uint64, err error) {
 	path := internal.GetProcPath()
 	fs, err := procfs.NewFS(path)
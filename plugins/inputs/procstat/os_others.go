@@ -4,6 +4,7 @@ package procstat
 
 import (
 	"errors"
+	"regexp"
 	"syscall"
 
 	gopsnet "github.com/shirou/gopsutil/v4/net"
@@ -28,6 +29,10 @@ func findByWindowsServices([]string) ([]processGroup, error) {
 	return nil, nil
 }
 
+func containerID(process, *regexp.Regexp) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
 func collectTotalReadWrite(process) (r, w uint64, err error) {
 	return 0, 0, errors.ErrUnsupported
 }
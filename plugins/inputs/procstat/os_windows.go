@@ -5,6 +5,7 @@ package procstat
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"syscall"
 	"unsafe"
 
@@ -85,6 +86,10 @@ func findByWindowsServices(services []string) ([]processGroup, error) {
 	return groups, nil
 }
 
+func containerID(process, *regexp.Regexp) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
 func collectTotalReadWrite(process) (r, w uint64, err error) {
 	return 0, 0, errors.ErrUnsupported
 }
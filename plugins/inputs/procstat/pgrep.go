@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -58,6 +59,11 @@ func (pg *pgrep) children(pid pid) ([]pid, error) {
 	return pg.find(args)
 }
 
+// pgrep cannot match on the environment so use the native implementation
+func (*pgrep) environ(key string, value *regexp.Regexp) ([]pid, error) {
+	return (&NativeFinder{}).environ(key, value)
+}
+
 func (pg *pgrep) find(args []string) ([]pid, error) {
 	// Execute pgrep with the given arguments
 	buf, err := exec.Command(pg.path, args...).Output()
@@ -3,11 +3,15 @@ package procstat
 import (
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"syscall"
 	"time"
 
+	gopscpu "github.com/shirou/gopsutil/v4/cpu"
 	gopsnet "github.com/shirou/gopsutil/v4/net"
 	gopsprocess "github.com/shirou/gopsutil/v4/process"
 
@@ -29,6 +33,7 @@ type pidFinder interface {
 	uid(user string) ([]pid, error)
 	fullPattern(path string) ([]pid, error)
 	children(pid pid) ([]pid, error)
+	environ(key string, value *regexp.Regexp) ([]pid, error)
 }
 
 type proc struct {
@@ -68,6 +73,56 @@ func (p *proc) percent(_ time.Duration) (float64, error) {
 	return cpuPerc, err
 }
 
+// addCmdlineTags sets the tags to the first capturing group of the
+// corresponding expression matching the command line and removes the tags
+// of expressions not matching
+func addCmdlineTags(tags map[string]string, cmdline string, expressions map[string]*regexp.Regexp) {
+	for tag, re := range expressions {
+		if match := re.FindStringSubmatch(cmdline); match != nil {
+			tags[tag] = match[1]
+		} else {
+			delete(tags, tag)
+		}
+	}
+}
+
+// threadMetrics creates a metric with the CPU times of each of the given
+// threads of the process, ordered by thread ID
+func threadMetrics(prefix string, tags map[string]string, id pid, threads map[int32]*gopscpu.TimesStat, t time.Time) []telegraf.Metric {
+	ids := make([]int32, 0, len(threads))
+	for tid := range threads {
+		ids = append(ids, tid)
+	}
+	slices.Sort(ids)
+
+	metrics := make([]telegraf.Metric, 0, len(ids))
+	for _, tid := range ids {
+		times := threads[tid]
+		threadTags := make(map[string]string, len(tags)+2)
+		for k, v := range tags {
+			threadTags[k] = v
+		}
+		threadTags["pid"] = strconv.Itoa(int(id))
+		threadTags["thread_id"] = strconv.Itoa(int(tid))
+
+		fields := map[string]interface{}{
+			prefix + "cpu_time_user":   times.User,
+			prefix + "cpu_time_system": times.System,
+		}
+		metrics = append(metrics, metric.New("procstat_threads", threadTags, fields, t))
+	}
+	return metrics
+}
+
+// limitUsedPercent returns the percentage of the soft limit in use or zero
+// if the process is not limited
+func limitUsedPercent(used, soft uint64) float64 {
+	if soft == 0 || soft == math.MaxUint64 {
+		return 0
+	}
+	return float64(used) / float64(soft) * 100
+}
+
 // Add metrics a single process
 func (p *proc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([]telegraf.Metric, error) {
 	if prefix != "" {
@@ -194,6 +249,9 @@ func (p *proc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([]tel
 				if name != "file_locks" { // gopsutil doesn't currently track the used file locks count
 					fields[prefix+name] = rlim.Used
 				}
+				if name == "num_fds" {
+					fields[prefix+"fd_limit_used_percent"] = limitUsedPercent(rlim.Used, rlim.Soft)
+				}
 			}
 		}
 	}
@@ -206,6 +264,7 @@ func (p *proc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([]tel
 		} else {
 			fields[prefix+"cmdline"] = cmdline
 		}
+		addCmdlineTags(p.tags, cmdline, cfg.cmdlineTags)
 	}
 
 	if cfg.tagging["pid"] {
@@ -241,6 +300,12 @@ func (p *proc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([]tel
 		}
 	}
 
+	if cfg.tagging["container"] {
+		if id, err := containerID(p, cfg.containerID); err == nil && id != "" {
+			p.tags["container_id"] = id
+		}
+	}
+
 	if _, exists := p.tags["process_name"]; !exists {
 		name, err := p.Name()
 		if err == nil {
@@ -250,6 +315,13 @@ func (p *proc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([]tel
 
 	metrics := []telegraf.Metric{metric.New("procstat", p.tags, fields, t)}
 
+	// Collect the CPU times of the individual threads if requested
+	if cfg.features["threads"] {
+		if threads, err := p.Threads(); err == nil {
+			metrics = append(metrics, threadMetrics(prefix, p.tags, pid(p.Pid), threads, t)...)
+		}
+	}
+
 	// Collect the socket statistics if requested
 	if cfg.features["sockets"] {
 		for _, protocol := range cfg.socketProtos {
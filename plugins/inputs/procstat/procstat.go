@@ -2,13 +2,16 @@
 package procstat
 
 import (
+	"bufio"
 	"bytes"
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"slices"
 	"strconv"
@@ -18,7 +21,9 @@ import (
 	gopsprocess "github.com/shirou/gopsutil/v4/process"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -28,35 +33,48 @@ var sampleConfig string
 // execCommand is so tests can mock out exec.Command usage.
 var execCommand = exec.Command
 
+// cgroupRoot is the mount point of the cgroup filesystem, tests can point it
+// to a fake hierarchy.
+var cgroupRoot = "/sys/fs/cgroup"
+
 type pid int32
 
 type Procstat struct {
-	PidFinder              string          `toml:"pid_finder"`
-	PidFile                string          `toml:"pid_file"`
-	Exe                    string          `toml:"exe"`
-	Pattern                string          `toml:"pattern"`
-	Prefix                 string          `toml:"prefix"`
-	CmdLineTag             bool            `toml:"cmdline_tag" deprecated:"1.29.0;1.40.0;use 'tag_with' instead"`
-	ProcessName            string          `toml:"process_name"`
-	User                   string          `toml:"user"`
-	SystemdUnit            string          `toml:"systemd_unit"`
-	SupervisorUnit         []string        `toml:"supervisor_unit" deprecated:"1.29.0;1.40.0;use 'supervisor_units' instead"`
-	SupervisorUnits        []string        `toml:"supervisor_units"`
-	IncludeSystemdChildren bool            `toml:"include_systemd_children"`
-	CGroup                 string          `toml:"cgroup"`
-	PidTag                 bool            `toml:"pid_tag" deprecated:"1.29.0;1.40.0;use 'tag_with' instead"`
-	WinService             string          `toml:"win_service"`
-	Mode                   string          `toml:"mode"`
-	Properties             []string        `toml:"properties"`
-	SocketProtocols        []string        `toml:"socket_protocols"`
-	TagWith                []string        `toml:"tag_with"`
-	Filter                 []filter        `toml:"filter"`
-	Log                    telegraf.Logger `toml:"-"`
-
-	finder    pidFinder
-	processes map[pid]process
-	cfg       collectionConfig
-	oldMode   bool
+	PidFinder              string            `toml:"pid_finder"`
+	PidFile                string            `toml:"pid_file"`
+	Exe                    string            `toml:"exe"`
+	Pattern                string            `toml:"pattern"`
+	Prefix                 string            `toml:"prefix"`
+	CmdLineTag             bool              `toml:"cmdline_tag" deprecated:"1.29.0;1.40.0;use 'tag_with' instead"`
+	ProcessName            string            `toml:"process_name"`
+	User                   string            `toml:"user"`
+	EnvKey                 string            `toml:"env_key"`
+	EnvValue               string            `toml:"env_value"`
+	SystemdUnit            string            `toml:"systemd_unit"`
+	SupervisorUnit         []string          `toml:"supervisor_unit" deprecated:"1.29.0;1.40.0;use 'supervisor_units' instead"`
+	SupervisorUnits        []string          `toml:"supervisor_units"`
+	IncludeSystemdChildren bool              `toml:"include_systemd_children"`
+	CGroup                 string            `toml:"cgroup"`
+	PidTag                 bool              `toml:"pid_tag" deprecated:"1.29.0;1.40.0;use 'tag_with' instead"`
+	WinService             string            `toml:"win_service"`
+	Mode                   string            `toml:"mode"`
+	ReportMode             string            `toml:"report_mode"`
+	Properties             []string          `toml:"properties"`
+	SocketProtocols        []string          `toml:"socket_protocols"`
+	TagWith                []string          `toml:"tag_with"`
+	ContainerIDPattern     string            `toml:"container_id_pattern"`
+	CmdlineTags            map[string]string `toml:"cmdline_tags"`
+	ThresholdField         string            `toml:"threshold_field"`
+	Threshold              float64           `toml:"threshold"`
+	Filter                 []filter          `toml:"filter"`
+	Log                    telegraf.Logger   `toml:"-"`
+
+	finder     pidFinder
+	processes  map[pid]process
+	ioCounters map[ioKey]ioSample
+	cfg        collectionConfig
+	oldMode    bool
+	envValue   *regexp.Regexp
 
 	createProcess func(pid) (process, error)
 }
@@ -66,6 +84,8 @@ type collectionConfig struct {
 	tagging      map[string]bool
 	features     map[string]bool
 	socketProtos []string
+	containerID  *regexp.Regexp
+	cmdlineTags  map[string]*regexp.Regexp
 }
 
 type pidsTags struct {
@@ -73,6 +93,19 @@ type pidsTags struct {
 	Tags map[string]string
 }
 
+// ioKey identifies a process across gathers, the creation time is used to
+// detect reuse of the PID by a different process
+type ioKey struct {
+	pid       pid
+	createdAt int64
+}
+
+type ioSample struct {
+	readBytes  uint64
+	writeBytes uint64
+	timestamp  time.Time
+}
+
 type processGroup struct {
 	processes []*gopsprocess.Process
 	tags      map[string]string
@@ -94,11 +127,28 @@ func (p *Procstat) Init() error {
 	// Configure metric collection features
 	p.cfg.solarisMode = strings.EqualFold(p.Mode, "solaris")
 
+	switch p.ReportMode {
+	case "":
+		p.ReportMode = "individual"
+	case "individual", "aggregate":
+	default:
+		return fmt.Errorf("invalid 'report_mode' setting %q", p.ReportMode)
+	}
+
 	// Convert tagging settings
 	p.cfg.tagging = make(map[string]bool, len(p.TagWith))
 	for _, tag := range p.TagWith {
 		switch tag {
 		case "cmdline", "pid", "ppid", "status", "user":
+		case "container":
+			if p.ContainerIDPattern == "" {
+				p.ContainerIDPattern = "[0-9a-f]{64}"
+			}
+			re, err := regexp.Compile(p.ContainerIDPattern)
+			if err != nil {
+				return fmt.Errorf("compiling 'container_id_pattern' failed: %w", err)
+			}
+			p.cfg.containerID = re
 		case "protocol", "state", "src", "src_port", "dest", "dest_port", "name": // socket only
 			if !slices.Contains(p.Properties, "sockets") {
 				return fmt.Errorf("socket tagging option %q specified without sockets enabled", tag)
@@ -109,11 +159,24 @@ func (p *Procstat) Init() error {
 		p.cfg.tagging[tag] = true
 	}
 
+	// Compile the expressions for extracting tags from the command line
+	p.cfg.cmdlineTags = make(map[string]*regexp.Regexp, len(p.CmdlineTags))
+	for tag, pattern := range p.CmdlineTags {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling 'cmdline_tags' expression for %q failed: %w", tag, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("'cmdline_tags' expression for %q does not contain a capturing group", tag)
+		}
+		p.cfg.cmdlineTags[tag] = re
+	}
+
 	// Convert collection properties
 	p.cfg.features = make(map[string]bool, len(p.Properties))
 	for _, prop := range p.Properties {
 		switch prop {
-		case "cpu", "limits", "memory", "mmap":
+		case "cpu", "io_rates", "limits", "memory", "mmap", "threads":
 		case "sockets":
 			if len(p.SocketProtocols) == 0 {
 				p.SocketProtocols = []string{"all"}
@@ -156,12 +219,29 @@ func (p *Procstat) Init() error {
 		switch {
 		case len(p.SupervisorUnits) > 0, p.SystemdUnit != "", p.WinService != "",
 			p.CGroup != "", p.PidFile != "", p.Exe != "", p.Pattern != "",
-			p.User != "":
+			p.User != "", p.EnvKey != "":
 			// Do nothing as those are valid settings
 		default:
 			return errors.New("require filter option but none set")
 		}
 
+		// Check environment matching
+		if p.EnvValue != "" && p.EnvKey == "" {
+			return errors.New("'env_value' requires 'env_key' to be set")
+		}
+		if p.EnvKey != "" {
+			if runtime.GOOS != "linux" && runtime.GOOS != "windows" {
+				return fmt.Errorf("matching by environment is not supported on %s", runtime.GOOS)
+			}
+			if p.EnvValue != "" {
+				re, err := regexp.Compile(p.EnvValue)
+				if err != nil {
+					return fmt.Errorf("compiling 'env_value' failed: %w", err)
+				}
+				p.envValue = re
+			}
+		}
+
 		// Instantiate the finder
 		switch p.PidFinder {
 		case "", "pgrep":
@@ -188,7 +268,7 @@ func (p *Procstat) Init() error {
 		// Check for mixed mode
 		switch {
 		case p.PidFile != "", p.Exe != "", p.Pattern != "", p.User != "",
-			p.SystemdUnit != "", len(p.SupervisorUnit) > 0,
+			p.EnvKey != "", p.SystemdUnit != "", len(p.SupervisorUnit) > 0,
 			len(p.SupervisorUnits) > 0, p.CGroup != "", p.WinService != "":
 			return errors.New("cannot operate in mixed mode with filters and old-style config")
 		}
@@ -204,6 +284,7 @@ func (p *Procstat) Init() error {
 
 	// Initialize the running process cache
 	p.processes = make(map[pid]process)
+	p.ioCounters = make(map[ioKey]ioSample)
 
 	return nil
 }
@@ -246,6 +327,7 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 			continue
 		}
 		count += len(r.PIDs)
+		var group []telegraf.Metric
 		for _, pid := range r.PIDs {
 			// Check if the process is still running
 			proc, err := p.createProcess(pid)
@@ -286,19 +368,38 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 				// metrics available
 				acc.AddError(err)
 			}
+			if p.cfg.features["io_rates"] && len(metrics) > 0 {
+				p.addIORates(pid, metrics[0])
+			}
+			if p.ReportMode == "aggregate" {
+				if len(metrics) > 0 {
+					group = append(group, metrics[0])
+				}
+				continue
+			}
+			if p.ThresholdField != "" && len(metrics) > 0 {
+				p.applyThreshold(metrics[0])
+			}
 			for _, m := range metrics {
 				acc.AddMetric(m)
 			}
 		}
-	}
 
-	// Cleanup processes that are not running anymore
-	for pid := range p.processes {
-		if !running[pid] {
-			delete(p.processes, pid)
+		if len(group) > 0 {
+			tags := make(map[string]string, len(r.Tags)+1)
+			for k, v := range r.Tags {
+				tags[k] = v
+			}
+			if p.ProcessName != "" {
+				tags["process_name"] = p.ProcessName
+			}
+			acc.AddMetric(p.aggregate(group, tags, now))
 		}
 	}
 
+	// Cleanup processes that are not running anymore
+	p.cleanup(running)
+
 	// Add lookup statistics-metric
 	fields := map[string]interface{}{
 		"pid_count":   count,
@@ -349,6 +450,7 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 		var count int
 		for _, g := range groups {
 			count += len(g.processes)
+			var group []telegraf.Metric
 			for _, gp := range g.processes {
 				// Skip over non-running processes
 				if running, err := gp.IsRunning(); err != nil || !running {
@@ -390,10 +492,34 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 					// metrics available
 					acc.AddError(err)
 				}
+				if p.cfg.features["io_rates"] && len(metrics) > 0 {
+					p.addIORates(pid, metrics[0])
+				}
+				if p.ReportMode == "aggregate" {
+					if len(metrics) > 0 {
+						group = append(group, metrics[0])
+					}
+					continue
+				}
+				if p.ThresholdField != "" && len(metrics) > 0 {
+					p.applyThreshold(metrics[0])
+				}
 				for _, m := range metrics {
 					acc.AddMetric(m)
 				}
 			}
+
+			if len(group) > 0 {
+				tags := make(map[string]string, len(g.tags)+2)
+				for k, v := range g.tags {
+					tags[k] = v
+				}
+				if p.ProcessName != "" {
+					tags["process_name"] = p.ProcessName
+				}
+				tags["filter"] = f.Name
+				acc.AddMetric(p.aggregate(group, tags, now))
+			}
 		}
 
 		// Add lookup statistics-metric
@@ -413,12 +539,158 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 	}
 
 	// Cleanup processes that are not running anymore across all filters/groups
+	p.cleanup(running)
+
+	return nil
+}
+
+// extractContainerID returns the container ID found in the given cgroup file
+// content of a process. The pattern is matched against the cgroup path of each
+// line and the first capturing group is used, or the whole match if the
+// pattern has no groups.
+func extractContainerID(r io.Reader, pattern *regexp.Regexp) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// Each line has the format "hierarchy-ID:controller-list:cgroup-path"
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		match := pattern.FindStringSubmatch(parts[2])
+		if match == nil {
+			continue
+		}
+		if len(match) > 1 {
+			return match[1], nil
+		}
+		return match[0], nil
+	}
+	return "", scanner.Err()
+}
+
+// cleanup removes the cached state of processes that are not running anymore
+func (p *Procstat) cleanup(running map[pid]bool) {
 	for pid := range p.processes {
 		if !running[pid] {
 			delete(p.processes, pid)
 		}
 	}
-	return nil
+	for key := range p.ioCounters {
+		if !running[key.pid] {
+			delete(p.ioCounters, key)
+		}
+	}
+}
+
+// addIORates computes the per-second read and write rates of the given
+// process metric using the I/O counters recorded in the previous gather
+func (p *Procstat) addIORates(id pid, m telegraf.Metric) {
+	prefix := p.Prefix
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	readBytes, found := m.GetField(prefix + "read_bytes")
+	if !found {
+		return
+	}
+	writeBytes, found := m.GetField(prefix + "write_bytes")
+	if !found {
+		return
+	}
+	createdAt, _ := m.GetField(prefix + "created_at")
+	created, _ := createdAt.(int64)
+
+	current := ioSample{timestamp: m.Time()}
+	current.readBytes, _ = readBytes.(uint64)
+	current.writeBytes, _ = writeBytes.(uint64)
+
+	key := ioKey{pid: id, createdAt: created}
+	previous, found := p.ioCounters[key]
+	p.ioCounters[key] = current
+	if !found {
+		return
+	}
+
+	// Skip the rate computation if the counters were reset
+	elapsed := current.timestamp.Sub(previous.timestamp).Seconds()
+	if elapsed <= 0 || current.readBytes < previous.readBytes || current.writeBytes < previous.writeBytes {
+		return
+	}
+	m.AddField(prefix+"read_bytes_rate", float64(current.readBytes-previous.readBytes)/elapsed)
+	m.AddField(prefix+"write_bytes_rate", float64(current.writeBytes-previous.writeBytes)/elapsed)
+}
+
+// aggregate sums the numeric fields of the given process metrics into a
+// single metric with the given tags. Per-process values such as the PID, the
+// creation time or the resource limits are not summed up.
+func (p *Procstat) aggregate(metrics []telegraf.Metric, tags map[string]string, t time.Time) telegraf.Metric {
+	prefix := p.Prefix
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	fields := make(map[string]interface{})
+	for _, m := range metrics {
+		for _, field := range m.FieldList() {
+			switch {
+			case field.Key == "pid", field.Key == prefix+"ppid", field.Key == prefix+"created_at",
+				field.Key == prefix+"fd_limit_used_percent":
+				continue
+			case strings.HasPrefix(field.Key, prefix+"rlimit_"):
+				continue
+			}
+
+			switch v := field.Value.(type) {
+			case int32:
+				sum, _ := fields[field.Key].(int64)
+				fields[field.Key] = sum + int64(v)
+			case int64:
+				sum, _ := fields[field.Key].(int64)
+				fields[field.Key] = sum + v
+			case uint64:
+				sum, _ := fields[field.Key].(uint64)
+				fields[field.Key] = sum + v
+			case float32:
+				sum, _ := fields[field.Key].(float64)
+				fields[field.Key] = sum + float64(v)
+			case float64:
+				sum, _ := fields[field.Key].(float64)
+				fields[field.Key] = sum + v
+			}
+		}
+	}
+
+	m := metric.New("procstat", tags, fields, t)
+	if p.ThresholdField != "" {
+		p.applyThreshold(m)
+	}
+	return m
+}
+
+// applyThreshold reduces the given process metric to a heartbeat containing
+// only the PID and the threshold field unless the threshold is exceeded
+func (p *Procstat) applyThreshold(m telegraf.Metric) {
+	key := p.ThresholdField
+	if p.Prefix != "" {
+		key = p.Prefix + "_" + key
+	}
+
+	if raw, found := m.GetField(key); found {
+		if v, err := internal.ToFloat64(raw); err == nil && v > p.Threshold {
+			return
+		}
+	}
+
+	remove := make([]string, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		if field.Key != key && field.Key != "pid" {
+			remove = append(remove, field.Key)
+		}
+	}
+	for _, k := range remove {
+		m.RemoveField(k)
+	}
 }
 
 // Get matching PIDs and their initial tags
@@ -465,6 +737,16 @@ func (p *Procstat) findPids() ([]pidsTags, error) {
 		}
 		tags := map[string]string{"user": p.User}
 		return []pidsTags{{pids, tags}}, nil
+	case p.EnvKey != "":
+		pids, err := p.finder.environ(p.EnvKey, p.envValue)
+		if err != nil {
+			return nil, err
+		}
+		tags := map[string]string{"env_key": p.EnvKey}
+		if p.EnvValue != "" {
+			tags["env_value"] = p.EnvValue
+		}
+		return []pidsTags{{pids, tags}}, nil
 	}
 	return nil, errors.New("no filter option set")
 }
@@ -561,8 +843,7 @@ func (p *Procstat) supervisorPIDs() ([]string, map[string]map[string]string, err
 
 func (p *Procstat) systemdUnitPIDs() ([]pidsTags, error) {
 	if p.IncludeSystemdChildren {
-		p.CGroup = "systemd/system.slice/" + p.SystemdUnit
-		return p.cgroupPIDs()
+		return p.systemdCgroupPIDs()
 	}
 
 	var pidTags []pidsTags
@@ -604,10 +885,37 @@ func (p *Procstat) simpleSystemdUnitPIDs() ([]pid, error) {
 	return pids, nil
 }
 
+// systemdCgroupPIDs collects all processes in the cgroup of the systemd unit
+// including the children of the main process. The unit's cgroup is located in
+// the hierarchy of the "systemd" controller (cgroup v1) if available and in
+// the unified hierarchy (cgroup v2) otherwise. Preferring cgroup v1 keeps the
+// "cgroup" tag of existing series unchanged.
+func (p *Procstat) systemdCgroupPIDs() ([]pidsTags, error) {
+	cgroup := "systemd/system.slice/" + p.SystemdUnit
+	if ok, err := isDir(filepath.Join(cgroupRoot, "systemd", "system.slice")); err != nil || !ok {
+		if ok, err := isDir(filepath.Join(cgroupRoot, "system.slice")); err == nil && ok {
+			cgroup = "system.slice/" + p.SystemdUnit
+		}
+	}
+
+	pidTags, err := findCgroupPIDs(cgroup)
+	if err != nil {
+		return nil, err
+	}
+	for _, pt := range pidTags {
+		pt.Tags["systemd_unit"] = p.SystemdUnit
+	}
+	return pidTags, nil
+}
+
 func (p *Procstat) cgroupPIDs() ([]pidsTags, error) {
-	procsPath := p.CGroup
+	return findCgroupPIDs(p.CGroup)
+}
+
+func findCgroupPIDs(cgroup string) ([]pidsTags, error) {
+	procsPath := cgroup
 	if procsPath[0] != '/' {
-		procsPath = "/sys/fs/cgroup/" + procsPath
+		procsPath = filepath.Join(cgroupRoot, procsPath)
 	}
 
 	items, err := filepath.Glob(procsPath)
@@ -621,7 +929,7 @@ func (p *Procstat) cgroupPIDs() ([]pidsTags, error) {
 		if err != nil {
 			return nil, err
 		}
-		tags := map[string]string{"cgroup": p.CGroup, "cgroup_full": item}
+		tags := map[string]string{"cgroup": cgroup, "cgroup_full": item}
 		pidTags = append(pidTags, pidsTags{pids, tags})
 	}
 
@@ -3,15 +3,18 @@ package procstat
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	gopscpu "github.com/shirou/gopsutil/v4/cpu"
 	gopsprocess "github.com/shirou/gopsutil/v4/process"
 	"github.com/stretchr/testify/require"
 
@@ -104,14 +107,21 @@ func (pg *testPgrep) fullPattern(_ string) ([]pid, error) {
 	return pg.pids, pg.err
 }
 
+func (pg *testPgrep) environ(string, *regexp.Regexp) ([]pid, error) {
+	return pg.pids, pg.err
+}
+
 func (pg *testPgrep) children(_ pid) ([]pid, error) {
 	pids := []pid{7311, 8111, 8112}
 	return pids, pg.err
 }
 
 type testProc struct {
-	procID pid
-	tags   map[string]string
+	procID     pid
+	cmdline    string
+	tags       map[string]string
+	readBytes  uint64
+	writeBytes uint64
 }
 
 func newTestProc(pid pid) (process, error) {
@@ -153,9 +163,9 @@ func (p *testProc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([
 		prefix + "major_faults":                 uint64(0),
 		prefix + "child_major_faults":           uint64(0),
 		prefix + "child_minor_faults":           uint64(0),
-		prefix + "read_bytes":                   uint64(0),
+		prefix + "read_bytes":                   p.readBytes,
 		prefix + "read_count":                   uint64(0),
-		prefix + "write_bytes":                  uint64(0),
+		prefix + "write_bytes":                  p.writeBytes,
 		prefix + "write_count":                  uint64(0),
 		prefix + "created_at":                   int64(0),
 	}
@@ -179,11 +189,16 @@ func (p *testProc) metrics(prefix string, cfg *collectionConfig, t time.Time) ([
 	}
 
 	// Add the tags as requested by the user
+	cmdline := p.cmdline
+	if cmdline == "" {
+		cmdline = "test_proc"
+	}
 	if cfg.tagging["cmdline"] {
-		tags["cmdline"] = "test_proc"
+		tags["cmdline"] = cmdline
 	} else {
-		fields[prefix+"cmdline"] = "test_proc"
+		fields[prefix+"cmdline"] = cmdline
 	}
+	addCmdlineTags(tags, cmdline, cfg.cmdlineTags)
 
 	if cfg.tagging["pid"] {
 		tags["pid"] = strconv.Itoa(int(p.procID))
@@ -486,6 +501,111 @@ func TestGather_Pattern(t *testing.T) {
 	require.Equal(t, pattern, acc.TagValue("procstat", "pattern"))
 }
 
+func TestGather_Environ(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "windows" {
+		t.Skip("Skipping test on unsupported platform")
+	}
+
+	p := Procstat{
+		EnvKey:        "APP_ROLE",
+		EnvValue:      "^worker$",
+		PidFinder:     "test",
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]pid{processID}),
+		createProcess: newTestProc,
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+	require.True(t, acc.HasTag("procstat", "env_key"))
+	require.Equal(t, "APP_ROLE", acc.TagValue("procstat", "env_key"))
+	require.Equal(t, "^worker$", acc.TagValue("procstat", "env_value"))
+}
+
+func TestInitEnvValueWithoutKey(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		EnvValue:      "worker",
+		PidFinder:     "test",
+		Log:           testutil.Logger{},
+		createProcess: newTestProc,
+	}
+	require.ErrorContains(t, p.Init(), "'env_value' requires 'env_key'")
+}
+
+func TestLimitUsedPercent(t *testing.T) {
+	require.InDelta(t, 25.0, limitUsedPercent(25, 100), 1e-9)
+	require.InDelta(t, 0.0, limitUsedPercent(25, math.MaxUint64), 1e-9)
+	require.InDelta(t, 0.0, limitUsedPercent(25, 0), 1e-9)
+}
+
+func TestThreadMetrics(t *testing.T) {
+	threads := map[int32]*gopscpu.TimesStat{
+		4712: {User: 1.5, System: 0.25},
+		4711: {User: 10.0, System: 2.5},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat_threads",
+			map[string]string{"process_name": "test_proc", "pid": "42", "thread_id": "4711"},
+			map[string]interface{}{"cpu_time_user": 10.0, "cpu_time_system": 2.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"procstat_threads",
+			map[string]string{"process_name": "test_proc", "pid": "42", "thread_id": "4712"},
+			map[string]interface{}{"cpu_time_user": 1.5, "cpu_time_system": 0.25},
+			time.Unix(0, 0),
+		),
+	}
+
+	tags := map[string]string{"process_name": "test_proc"}
+	actual := threadMetrics("", tags, processID, threads, time.Unix(0, 0))
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Equal(t, map[string]string{"process_name": "test_proc"}, tags)
+}
+
+func TestGather_CmdlineTags(t *testing.T) {
+	p := Procstat{
+		Exe:       exe,
+		PidFinder: "test",
+		CmdlineTags: map[string]string{
+			"shard":  `--shard=(\d+)`,
+			"region": `--region=(\w+)`,
+			"mode":   `--(primary|replica)|--mode=(\w+)`,
+		},
+		Log:    testutil.Logger{},
+		finder: newTestFinder([]pid{processID}),
+		createProcess: func(id pid) (process, error) {
+			return &testProc{
+				procID:  id,
+				cmdline: "worker --shard=3 --primary",
+				tags:    make(map[string]string),
+			}, nil
+		},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+	require.Equal(t, "3", acc.TagValue("procstat", "shard"))
+	require.Equal(t, "primary", acc.TagValue("procstat", "mode"))
+	require.False(t, acc.HasTag("procstat", "region"))
+}
+
+func TestInitInvalidCmdlineTags(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		CmdlineTags:   map[string]string{"shard": `--shard=\d+`},
+		Log:           testutil.Logger{},
+		createProcess: newTestProc,
+	}
+	require.ErrorContains(t, p.Init(), "does not contain a capturing group")
+}
+
 func TestGather_PidFile(t *testing.T) {
 	pidfile := "/path/to/pidfile"
 
@@ -593,6 +713,157 @@ func TestGather_cgroupPIDs(t *testing.T) {
 	}
 }
 
+func TestContainerIDTag(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("container tagging is only supported on linux")
+	}
+
+	id := "3f2a4c1e9b7d6a5f8e0c2b4d6f8a0c2e4b6d8f0a2c4e6b8d0f2a4c6e8b0d2f4a"
+	content := "12:memory:/system.slice/docker-" + id + ".scope\n" +
+		"11:cpu,cpuacct:/system.slice/docker-" + id + ".scope\n" +
+		"0::/system.slice/docker-" + id + ".scope\n"
+
+	td := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(td, strconv.Itoa(int(processID))), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(td, strconv.Itoa(int(processID)), "cgroup"), []byte(content), 0640))
+	t.Setenv("HOST_PROC", td)
+
+	p := Procstat{
+		Exe:       exe,
+		PidFinder: "test",
+		TagWith:   []string{"container"},
+		Log:       testutil.Logger{},
+		finder:    newTestFinder([]pid{processID}),
+	}
+	require.NoError(t, p.Init())
+
+	proc, err := newTestProc(processID)
+	require.NoError(t, err)
+	actual, err := containerID(proc, p.cfg.containerID)
+	require.NoError(t, err)
+	require.Equal(t, id, actual)
+}
+
+func TestExtractContainerID(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		content  string
+		expected string
+	}{
+		{
+			name:     "docker cgroup v1",
+			pattern:  "[0-9a-f]{64}",
+			content:  "4:pids:/docker/0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+			expected: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+		{
+			name:     "capturing group",
+			pattern:  `cri-containerd-([0-9a-f]+)\.scope`,
+			content:  "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc123.scope\n",
+			expected: "abc123",
+		},
+		{
+			name:    "not in a container",
+			pattern: "[0-9a-f]{64}",
+			content: "0::/user.slice/user-1000.slice/session-2.scope\n",
+		},
+		{
+			name:    "malformed lines",
+			pattern: ".+",
+			content: "garbage\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := extractContainerID(strings.NewReader(tt.content), regexp.MustCompile(tt.pattern))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInitInvalidContainerIDPattern(t *testing.T) {
+	p := Procstat{
+		Exe:                exe,
+		PidFinder:          "test",
+		TagWith:            []string{"container"},
+		ContainerIDPattern: "[",
+		Log:                testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), "compiling 'container_id_pattern' failed")
+}
+
+func TestGather_systemdUnitCgroupPIDs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no cgroups in windows")
+	}
+
+	// Fake a unified (v2) cgroup hierarchy
+	td := t.TempDir()
+	unit := filepath.Join(td, "system.slice", "test.service")
+	require.NoError(t, os.MkdirAll(unit, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(unit, "cgroup.procs"), []byte("1234\n5678\n"), 0640))
+
+	root := cgroupRoot
+	cgroupRoot = td
+	defer func() { cgroupRoot = root }()
+
+	p := Procstat{
+		SystemdUnit:            "test.service",
+		IncludeSystemdChildren: true,
+		PidFinder:              "test",
+		Properties:             []string{"cpu", "memory", "mmap"},
+		Log:                    testutil.Logger{},
+		finder:                 newTestFinder([]pid{processID}),
+	}
+	require.NoError(t, p.Init())
+
+	pidsTags, err := p.findPids()
+	require.NoError(t, err)
+	require.Len(t, pidsTags, 1)
+	require.Equal(t, []pid{1234, 5678}, pidsTags[0].PIDs)
+	require.Equal(t, "test.service", pidsTags[0].Tags["systemd_unit"])
+	require.Equal(t, unit, pidsTags[0].Tags["cgroup_full"])
+}
+
+func TestGather_systemdUnitCgroupV1PIDs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no cgroups in windows")
+	}
+
+	// Fake a hierarchy with both cgroup v1 and v2 being available
+	td := t.TempDir()
+	unit := filepath.Join(td, "systemd", "system.slice", "test.service")
+	require.NoError(t, os.MkdirAll(unit, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(unit, "cgroup.procs"), []byte("1234\n5678\n"), 0640))
+	require.NoError(t, os.MkdirAll(filepath.Join(td, "system.slice", "test.service"), 0750))
+
+	root := cgroupRoot
+	cgroupRoot = td
+	defer func() { cgroupRoot = root }()
+
+	p := Procstat{
+		SystemdUnit:            "test.service",
+		IncludeSystemdChildren: true,
+		PidFinder:              "test",
+		Properties:             []string{"cpu", "memory", "mmap"},
+		Log:                    testutil.Logger{},
+		finder:                 newTestFinder([]pid{processID}),
+	}
+	require.NoError(t, p.Init())
+
+	// The cgroup tag must be the same as before adding cgroup v2 support
+	pidsTags, err := p.findPids()
+	require.NoError(t, err)
+	require.Len(t, pidsTags, 1)
+	require.Equal(t, []pid{1234, 5678}, pidsTags[0].PIDs)
+	require.Equal(t, "systemd/system.slice/test.service", pidsTags[0].Tags["cgroup"])
+	require.Equal(t, "test.service", pidsTags[0].Tags["systemd_unit"])
+	require.Equal(t, unit, pidsTags[0].Tags["cgroup_full"])
+}
+
 func TestProcstatLookupMetric(t *testing.T) {
 	p := Procstat{
 		Exe:           "-Gsys",
@@ -609,6 +880,54 @@ func TestProcstatLookupMetric(t *testing.T) {
 	require.NotEmpty(t, acc.GetTelegrafMetrics())
 }
 
+func TestProcstatLookupPidCount(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]pid{1, 2, 3}),
+		createProcess: newTestProc,
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+	m, found := acc.Get("procstat_lookup")
+	require.True(t, found)
+	require.EqualValues(t, 3, m.Fields["pid_count"])
+}
+
+func TestProcstatLookupPidCountError(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Log:           testutil.Logger{},
+		finder:        &testPgrep{err: errors.New("lookup failed")},
+		createProcess: newTestProc,
+	}
+	require.NoError(t, p.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat_lookup",
+			map[string]string{
+				"pid_finder": "test",
+				"result":     "lookup_error",
+			},
+			map[string]interface{}{
+				"pid_count":   int64(0),
+				"result_code": int64(1),
+				"running":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, p.Gather(&acc), "lookup failed")
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 func TestGather_SameTimestamps(t *testing.T) {
 	pidfile := "/path/to/pidfile"
 
@@ -631,6 +950,169 @@ func TestGather_SameTimestamps(t *testing.T) {
 	require.Equal(t, procstat.Time, procstatLookup.Time)
 }
 
+func TestGather_IORates(t *testing.T) {
+	proc := &testProc{
+		procID: processID,
+		tags:   make(map[string]string),
+	}
+
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Properties:    []string{"io_rates"},
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]pid{processID}),
+		createProcess: func(pid) (process, error) { return proc, nil },
+	}
+	require.NoError(t, p.Init())
+
+	// The rates require two gathers
+	var acc testutil.Accumulator
+	proc.readBytes, proc.writeBytes = 1024, 2048
+	require.NoError(t, p.Gather(&acc))
+	first, found := acc.Get("procstat")
+	require.True(t, found)
+	require.NotContains(t, first.Fields, "read_bytes_rate")
+	require.NotContains(t, first.Fields, "write_bytes_rate")
+
+	time.Sleep(10 * time.Millisecond)
+	acc.ClearMetrics()
+	proc.readBytes, proc.writeBytes = 3072, 2560
+	require.NoError(t, p.Gather(&acc))
+	second, found := acc.Get("procstat")
+	require.True(t, found)
+
+	elapsed := second.Time.Sub(first.Time).Seconds()
+	require.InDelta(t, 2048/elapsed, second.Fields["read_bytes_rate"], 1e-6)
+	require.InDelta(t, 512/elapsed, second.Fields["write_bytes_rate"], 1e-6)
+
+	// A counter reset must not produce a rate
+	acc.ClearMetrics()
+	proc.readBytes, proc.writeBytes = 0, 0
+	require.NoError(t, p.Gather(&acc))
+	third, found := acc.Get("procstat")
+	require.True(t, found)
+	require.NotContains(t, third.Fields, "read_bytes_rate")
+	require.NotContains(t, third.Fields, "write_bytes_rate")
+}
+
+func TestGather_Threshold(t *testing.T) {
+	proc := &testProc{
+		procID: processID,
+		tags:   make(map[string]string),
+	}
+
+	p := Procstat{
+		Exe:            exe,
+		PidFinder:      "test",
+		ThresholdField: "read_bytes",
+		Threshold:      1000,
+		Log:            testutil.Logger{},
+		finder:         newTestFinder([]pid{processID}),
+		createProcess:  func(pid) (process, error) { return proc, nil },
+	}
+	require.NoError(t, p.Init())
+
+	// Below the threshold only a heartbeat is emitted
+	var acc testutil.Accumulator
+	proc.readBytes = 500
+	require.NoError(t, p.Gather(&acc))
+	m, found := acc.Get("procstat")
+	require.True(t, found)
+	require.Equal(t, map[string]interface{}{
+		"pid":        int64(processID),
+		"read_bytes": uint64(500),
+	}, m.Fields)
+
+	// Above the threshold all fields are emitted
+	acc.ClearMetrics()
+	proc.readBytes = 1500
+	require.NoError(t, p.Gather(&acc))
+	m, found = acc.Get("procstat")
+	require.True(t, found)
+	require.Equal(t, uint64(1500), m.Fields["read_bytes"])
+	require.Contains(t, m.Fields, "num_threads")
+	require.Contains(t, m.Fields, "write_bytes")
+}
+
+func TestGather_Aggregate(t *testing.T) {
+	p := Procstat{
+		Exe:        exe,
+		PidFinder:  "test",
+		ReportMode: "aggregate",
+		Properties: []string{"cpu", "memory"},
+		Log:        testutil.Logger{},
+		finder:     newTestFinder([]pid{1, 2, 3}),
+		createProcess: func(id pid) (process, error) {
+			return &testProc{
+				procID:     id,
+				tags:       make(map[string]string),
+				readBytes:  uint64(id) * 100,
+				writeBytes: uint64(id) * 10,
+			}, nil
+		},
+	}
+	require.NoError(t, p.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat",
+			map[string]string{"exe": "foo"},
+			map[string]interface{}{
+				"child_major_faults":           uint64(0),
+				"child_minor_faults":           uint64(0),
+				"cpu_time_iowait":              float64(0),
+				"cpu_time_system":              float64(0),
+				"cpu_time_user":                float64(0),
+				"cpu_usage":                    float64(0),
+				"involuntary_context_switches": int64(0),
+				"major_faults":                 uint64(0),
+				"memory_rss":                   uint64(0),
+				"memory_usage":                 float64(0),
+				"memory_vms":                   uint64(0),
+				"minor_faults":                 uint64(0),
+				"num_fds":                      int64(0),
+				"num_threads":                  int64(0),
+				"read_bytes":                   uint64(600),
+				"read_count":                   uint64(0),
+				"voluntary_context_switches":   int64(0),
+				"write_bytes":                  uint64(60),
+				"write_count":                  uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"procstat_lookup",
+			map[string]string{
+				"exe":        "foo",
+				"pid_finder": "test",
+				"result":     "success",
+			},
+			map[string]interface{}{
+				"pid_count":   int64(3),
+				"result_code": int64(0),
+				"running":     int64(3),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInitInvalidReportMode(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		ReportMode:    "summary",
+		Log:           testutil.Logger{},
+		createProcess: newTestProc,
+	}
+	require.ErrorContains(t, p.Init(), "invalid 'report_mode' setting")
+}
+
 func TestGather_supervisorUnitPIDs(t *testing.T) {
 	p := Procstat{
 		SupervisorUnits: []string{"TestGather_supervisorUnitPIDs"},
@@ -96,3 +96,36 @@ func parseConnectionStringURL(connectionString string) (sqlInstance, databaseNam
 
 	return sqlInstance, databaseName
 }
+
+// withApplicationIntent returns the connection string with the given
+// application intent set, overriding any existing setting. An empty intent
+// returns the connection string unchanged.
+func withApplicationIntent(connectionString, intent string) (string, error) {
+	if intent == "" {
+		return connectionString, nil
+	}
+
+	trimmedConnectionString := strings.TrimSpace(connectionString)
+	if strings.HasPrefix(trimmedConnectionString, "sqlserver://") {
+		u, err := url.Parse(trimmedConnectionString)
+		if err != nil {
+			return "", err
+		}
+		query := u.Query()
+		for key := range query {
+			if strings.EqualFold("applicationintent", key) {
+				query.Del(key)
+			}
+		}
+		query.Set("ApplicationIntent", intent)
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	// For "key=value;" connection strings (optionally prefixed with "odbc:")
+	// the last occurrence of a key takes precedence
+	if trimmedConnectionString != "" && !strings.HasSuffix(trimmedConnectionString, ";") {
+		trimmedConnectionString += ";"
+	}
+	return trimmedConnectionString + "ApplicationIntent=" + intent + ";", nil
+}
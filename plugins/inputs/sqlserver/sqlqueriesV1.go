@@ -934,7 +934,72 @@ IF OBJECT_ID('tempdb..#CCounters') IS NOT NULL DROP TABLE #CCounters;
 IF OBJECT_ID('tempdb..#PCounters') IS NOT NULL DROP TABLE #PCounters;
 `
 
-const sqlWaitStatsCategorized string = `SET DEADLOCK_PRIORITY -10;
+// sqlPerformanceCountersSnapshot reports the raw cumulative counter values
+// without sampling twice, leaving the rate calculation to the consumer.
+const sqlPerformanceCountersSnapshot string = `SET DEADLOCK_PRIORITY -10;
+SET NOCOUNT ON;
+SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED;
+IF OBJECT_ID('tempdb..#CCounters') IS NOT NULL DROP TABLE #CCounters
+CREATE TABLE #CCounters
+(
+	object_name nvarchar(128),
+	counter_name nvarchar(128),
+	instance_name nvarchar(128),
+	cntr_value bigint,
+	cntr_type INT,
+	Primary Key(object_name, counter_name, instance_name)
+);
+INSERT #CCounters
+SELECT DISTINCT RTrim(spi.object_name) object_name
+, RTrim(spi.counter_name) counter_name
+, RTrim(spi.instance_name) instance_name
+, spi.cntr_value
+, spi.cntr_type
+FROM sys.dm_os_performance_counters spi
+WHERE spi.object_name NOT LIKE '%Backup Device%'
+	AND NOT EXISTS (SELECT 1 FROM sys.databases WHERE Name = spi.instance_name);
+
+SELECT
+ measurement = cc.counter_name
+	+ CASE WHEN LEN(cc.instance_name) > 0 THEN ' | ' + cc.instance_name ELSE '' END
+	+ ' | '
+	+ SUBSTRING( cc.object_name, CHARINDEX(':',  cc.object_name) + 1, LEN( cc.object_name) - CHARINDEX(':',  cc.object_name))
+-- tags
+, servername = REPLACE(@@SERVERNAME, '\', ':')
+, type = 'Performance counters'
+-- value
+, value = CAST(CASE cc.cntr_type
+    When 537003264 Then IsNull(Cast(cc.cntr_value as decimal(19,4)) / NullIf(cbc.cntr_value, 0), 0) -- Ratio
+    Else cc.cntr_value End as bigint)
+FROM #CCounters cc
+LEFT JOIN #CCounters cbc On cc.object_name = cbc.object_name
+        And (Case When cc.counter_name Like '%(ms)' Then Replace(cc.counter_name, ' (ms)',' Base')
+                  When cc.object_name like '%FileTable' Then Replace(cc.counter_name, 'Avg ','') + ' base'
+                  When cc.counter_name = 'Worktables From Cache Ratio' Then 'Worktables From Cache Base'
+                  When cc.counter_name = 'Avg. Length of Batched Writes' Then 'Avg. Length of Batched Writes BS'
+                  Else cc.counter_name + ' base'
+             End) = cbc.counter_name
+        And cc.instance_name = cbc.instance_name
+        And cc.cntr_type = 537003264
+        And cbc.cntr_type = 1073939712
+
+IF OBJECT_ID('tempdb..#CCounters') IS NOT NULL DROP TABLE #CCounters;
+`
+
+// sqlWaitStatsCategorized samples the wait statistics twice with a delay in between
+// and reports the difference, while sqlWaitStatsCategorizedSnapshot reports the
+// cumulative values in a single pass without any delay.
+const sqlWaitStatsCategorized string = sqlWaitStatsCategorizedSetup + sqlWaitStatsCategorizedDelta + sqlWaitStatsCategorizedOutput
+
+const sqlWaitStatsCategorizedSnapshot string = sqlWaitStatsCategorizedSetup + sqlWaitStatsCategorizedCumulative + sqlWaitStatsCategorizedOutput
+
+// sqlWaitStatsDetail and sqlWaitStatsDetailSnapshot report the wait statistics
+// per wait type instead of pivoting them into categories.
+const sqlWaitStatsDetail string = sqlWaitStatsCategorizedSetup + sqlWaitStatsSample + sqlWaitStatsDetailDelta
+
+const sqlWaitStatsDetailSnapshot string = sqlWaitStatsCategorizedSetup + sqlWaitStatsDetailCumulative
+
+const sqlWaitStatsCategorizedSetup string = `SET DEADLOCK_PRIORITY -10;
 SET NOCOUNT ON;
 SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED
 DECLARE @secondsBetween tinyint = 5
@@ -1204,7 +1269,9 @@ INSERT @w4 (WaitType, WaitCategory) VALUES ('ABR', 'OTHER') ,
 , ('XE_TIMER_TASK_DONE' , 'XEVENT');
 
 
-INSERT @w1 (WaitType, WaitTimeInMs, WaitTaskCount, CollectionDate)
+`
+
+const sqlWaitStatsSample string = `INSERT @w1 (WaitType, WaitTimeInMs, WaitTaskCount, CollectionDate)
 SELECT
   WaitType = wait_type  collate SQL_Latin1_General_CP1_CI_AS
 , WaitTimeInMs = SUM(wait_time_ms)
@@ -1233,9 +1300,9 @@ WHERE [wait_type]  collate SQL_Latin1_General_CP1_CI_AS NOT IN
 )
 AND [waiting_tasks_count] > 0
 GROUP BY wait_type;
+`
 
-
-INSERT @w5 (WaitCategory, WaitTimeInMs, WaitTaskCount)
+const sqlWaitStatsCategorizedDelta string = sqlWaitStatsSample + `INSERT @w5 (WaitCategory, WaitTimeInMs, WaitTaskCount)
 SELECT WaitCategory
 , WaitTimeInMs = SUM(WaitTimeInMs)
 , WaitTaskCount = SUM(WaitTaskCount)
@@ -1252,7 +1319,63 @@ LEFT JOIN @w4 T4 ON T4.WaitType = T1.WaitType
 WHERE T2.WaitTaskCount - T1.WaitTaskCount > 0
 ) as G
 GROUP BY G.WaitCategory;
+`
+
+const sqlWaitStatsCategorizedCumulative string = `INSERT @w5 (WaitCategory, WaitTimeInMs, WaitTaskCount)
+SELECT
+  WaitCategory = ISNULL(T4.WaitCategory, 'OTHER')
+, WaitTimeInMs = SUM(ws.wait_time_ms)
+, WaitTaskCount = SUM(ws.waiting_tasks_count)
+FROM sys.dm_os_wait_stats ws
+LEFT JOIN @w4 T4 ON T4.WaitType = ws.wait_type collate SQL_Latin1_General_CP1_CI_AS
+WHERE ws.[wait_type] collate SQL_Latin1_General_CP1_CI_AS NOT IN
+(
+	SELECT WaitType FROM  @w3
+)
+AND ws.[waiting_tasks_count] > 0
+GROUP BY ISNULL(T4.WaitCategory, 'OTHER');
+`
+
+const sqlWaitStatsDetailDelta string = `
+SELECT
+---- measurement
+  measurement = 'Wait stats'
+---- tags
+, servername = REPLACE(@@SERVERNAME, '\', ':')
+, type = 'Wait stats'
+, wait_type = T1.WaitType
+, wait_category = ISNULL(T4.WaitCategory, 'OTHER')
+---- values
+, wait_time_ms = (T2.WaitTimeInMs - T1.WaitTimeInMs)
+, waiting_tasks_count = (T2.WaitTaskCount - T1.WaitTaskCount)
+FROM @w1 T1
+INNER JOIN @w2 T2 ON T2.WaitType = T1.WaitType
+LEFT JOIN @w4 T4 ON T4.WaitType = T1.WaitType
+WHERE T2.WaitTaskCount - T1.WaitTaskCount > 0;
+`
+
+const sqlWaitStatsDetailCumulative string = `
+SELECT
+---- measurement
+  measurement = 'Wait stats'
+---- tags
+, servername = REPLACE(@@SERVERNAME, '\', ':')
+, type = 'Wait stats'
+, wait_type = ws.wait_type collate SQL_Latin1_General_CP1_CI_AS
+, wait_category = ISNULL(T4.WaitCategory, 'OTHER')
+---- values
+, wait_time_ms = ws.wait_time_ms
+, waiting_tasks_count = ws.waiting_tasks_count
+FROM sys.dm_os_wait_stats ws
+LEFT JOIN @w4 T4 ON T4.WaitType = ws.wait_type collate SQL_Latin1_General_CP1_CI_AS
+WHERE ws.[wait_type] collate SQL_Latin1_General_CP1_CI_AS NOT IN
+(
+	SELECT WaitType FROM  @w3
+)
+AND ws.[waiting_tasks_count] > 0;
+`
 
+const sqlWaitStatsCategorizedOutput string = `
 
 
 SELECT
@@ -7,6 +7,8 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"maps"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -40,22 +42,45 @@ const (
 	healthMetricDatabaseType      = "database_type"
 
 	sqlAzureResourceID = "https://database.windows.net/"
+
+	sqlServerVersion = "SELECT CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128)), CAST(SERVERPROPERTY('Edition') AS nvarchar(128))"
 )
 
+var quotedNameRe = regexp.MustCompile(`'([^']+)'`)
+
+// errEncryptedColumns marks queries returning no or incomplete data due to
+// accessing columns protected by Always Encrypted
+var errEncryptedColumns = errors.New("query accesses encrypted columns")
+
+// optionalQueries are only collected if explicitly mentioned in the
+// include_query list
+var optionalQueries = []string{"SQLServerQueryStore", "SQLServerSchedulerDetail"}
+
 type SQLServer struct {
-	Servers      []*config.Secret `toml:"servers"`
-	QueryTimeout config.Duration  `toml:"query_timeout"`
-	AuthMethod   string           `toml:"auth_method"`
-	ClientID     string           `toml:"client_id"`
-	QueryVersion int              `toml:"query_version" deprecated:"1.16.0;1.35.0;use 'database_type' instead"`
-	AzureDB      bool             `toml:"azuredb" deprecated:"1.16.0;1.35.0;use 'database_type' instead"`
-	DatabaseType string           `toml:"database_type"`
-	IncludeQuery []string         `toml:"include_query"`
-	ExcludeQuery []string         `toml:"exclude_query"`
-	HealthMetric bool             `toml:"health_metric"`
-	Log          telegraf.Logger  `toml:"-"`
+	Servers                 []*config.Secret `toml:"servers"`
+	QueryTimeout            config.Duration  `toml:"query_timeout"`
+	AuthMethod              string           `toml:"auth_method"`
+	ClientID                string           `toml:"client_id"`
+	QueryVersion            int              `toml:"query_version" deprecated:"1.16.0;1.35.0;use 'database_type' instead"`
+	AzureDB                 bool             `toml:"azuredb" deprecated:"1.16.0;1.35.0;use 'database_type' instead"`
+	DatabaseType            string           `toml:"database_type"`
+	IncludeQuery            []string         `toml:"include_query"`
+	ExcludeQuery            []string         `toml:"exclude_query"`
+	HealthMetric            bool             `toml:"health_metric"`
+	WaitStatsSampling       string           `toml:"wait_stats_sampling"`
+	WaitStatsDetail         bool             `toml:"wait_stats_detail"`
+	ApplicationIntent       string           `toml:"application_intent"`
+	CircuitBreakerThreshold int              `toml:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  config.Duration  `toml:"circuit_breaker_cooldown"`
+	IncludeVersionTags      bool             `toml:"include_version_tags"`
+	FieldsInclude           []string         `toml:"fields_include"`
+	FieldsExclude           []string         `toml:"fields_exclude"`
+	Log                     telegraf.Logger  `toml:"-"`
 
 	pools       []*sql.DB
+	breakers    []*circuitBreaker
+	versionTags map[int]map[string]string
+	fieldFilter filter.Filter
 	queries     mapQuery
 	adalToken   *adal.Token
 	muCacheLock sync.RWMutex
@@ -66,6 +91,8 @@ type query struct {
 	Script         string
 	ResultByRow    bool
 	OrderedColumns []string
+	// Tags of the instance added to all metrics of the query
+	Tags map[string]string
 }
 
 type mapQuery map[string]query
@@ -76,6 +103,13 @@ type healthMetric struct {
 	successfulQueries int
 }
 
+// circuitBreaker tracks the consecutive failures of an instance to skip
+// querying it for a cooldown period once the instance seems to be down
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+}
+
 type scanner interface {
 	Scan(dest ...interface{}) error
 }
@@ -90,6 +124,40 @@ func (s *SQLServer) Init() error {
 		s.Servers = append(s.Servers, &srv)
 	}
 
+	switch s.WaitStatsSampling {
+	case "":
+		s.WaitStatsSampling = "delta"
+	case "delta", "snapshot":
+	default:
+		return fmt.Errorf("invalid wait_stats_sampling %q", s.WaitStatsSampling)
+	}
+
+	switch s.ApplicationIntent {
+	case "", "ReadOnly", "ReadWrite":
+	default:
+		return fmt.Errorf("invalid application_intent %q", s.ApplicationIntent)
+	}
+
+	// Unknown query names are most likely typos
+	if err := s.checkQueryNames(); err != nil {
+		return err
+	}
+
+	if s.CircuitBreakerThreshold < 0 {
+		return fmt.Errorf("invalid circuit_breaker_threshold %d", s.CircuitBreakerThreshold)
+	}
+	if s.CircuitBreakerCooldown == 0 {
+		s.CircuitBreakerCooldown = config.Duration(5 * time.Minute)
+	}
+
+	if len(s.FieldsInclude) > 0 || len(s.FieldsExclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(s.FieldsInclude, s.FieldsExclude)
+		if err != nil {
+			return fmt.Errorf("creating field filter failed: %w", err)
+		}
+		s.fieldFilter = f
+	}
+
 	return nil
 }
 
@@ -115,11 +183,17 @@ func (s *SQLServer) Start(acc telegraf.Accumulator) error {
 				continue
 			}
 
+			connectionString, err := withApplicationIntent(dsn.String(), s.ApplicationIntent)
+			dsn.Destroy()
+			if err != nil {
+				acc.AddError(fmt.Errorf("setting application intent failed: %w", err))
+				continue
+			}
+
 			// Use the DSN (connection string) directly. In this case,
 			// empty username/password causes use of Windows
 			// integrated authentication.
-			pool, err = sql.Open("mssql", dsn.String())
-			dsn.Destroy()
+			pool, err = sql.Open("mssql", connectionString)
 			if err != nil {
 				acc.AddError(err)
 				continue
@@ -146,8 +220,13 @@ func (s *SQLServer) Start(acc telegraf.Accumulator) error {
 				acc.AddError(err)
 				continue
 			}
-			connector, err := mssql.NewAccessTokenConnector(dsn.String(), tokenProvider)
+			connectionString, err := withApplicationIntent(dsn.String(), s.ApplicationIntent)
 			dsn.Destroy()
+			if err != nil {
+				acc.AddError(fmt.Errorf("setting application intent failed: %w", err))
+				continue
+			}
+			connector, err := mssql.NewAccessTokenConnector(connectionString, tokenProvider)
 			if err != nil {
 				acc.AddError(fmt.Errorf("error creating the SQL connector: %w", err))
 				continue
@@ -159,6 +238,7 @@ func (s *SQLServer) Start(acc telegraf.Accumulator) error {
 		}
 
 		s.pools = append(s.pools, pool)
+		s.breakers = append(s.breakers, &circuitBreaker{})
 	}
 
 	return nil
@@ -169,6 +249,9 @@ func (s *SQLServer) Gather(acc telegraf.Accumulator) error {
 	var mutex sync.Mutex
 	var healthMetrics = make(map[string]*healthMetric)
 
+	now := time.Now()
+	attempted := make([]bool, len(s.pools))
+	succeeded := make([]bool, len(s.pools))
 	for i, pool := range s.pools {
 		dnsSecret, err := s.Servers[i].Get()
 		if err != nil {
@@ -178,25 +261,70 @@ func (s *SQLServer) Gather(acc telegraf.Accumulator) error {
 		dsn := dnsSecret.String()
 		dnsSecret.Destroy()
 
+		// Skip instances with an open circuit breaker and only report health
+		if s.CircuitBreakerThreshold > 0 && now.Before(s.breakers[i].openUntil) {
+			serverName, databaseName := getConnectionIdentifiers(dsn)
+			s.Log.Debugf("Skipping server %q and database %q until %v after %d consecutive failures",
+				serverName, databaseName, s.breakers[i].openUntil, s.breakers[i].failures)
+			if s.HealthMetric {
+				healthMetrics[dsn] = &healthMetric{}
+			}
+			continue
+		}
+		attempted[i] = true
+
+		// Determine the version of the instance once as it cannot change
+		// without reconnecting
+		if s.IncludeVersionTags && s.versionTags[i] == nil {
+			if s.versionTags == nil {
+				s.versionTags = make(map[int]map[string]string, len(s.pools))
+			}
+			tags, err := s.queryVersionTags(pool)
+			if err != nil {
+				serverName, databaseName := getConnectionIdentifiers(dsn)
+				s.Log.Warnf("Querying version of server %q and database %q failed: %v", serverName, databaseName, err)
+			}
+			s.versionTags[i] = tags
+		}
+
 		for _, q := range s.queries {
+			q.Tags = s.versionTags[i]
 			wg.Add(1)
-			go func(pool *sql.DB, q query, dsn string) {
+			go func(i int, pool *sql.DB, q query, dsn string) {
 				defer wg.Done()
 				queryError := s.gatherServer(pool, q, acc, dsn)
 
+				// Queries failing due to encrypted columns are logged as warning
+				// and only counted as failed in the health metric, the server
+				// itself responded fine
+				encrypted := errors.Is(queryError, errEncryptedColumns)
+
+				mutex.Lock()
+				if queryError == nil || encrypted {
+					succeeded[i] = true
+				}
 				if s.HealthMetric {
-					mutex.Lock()
 					gatherHealth(healthMetrics, dsn, queryError)
-					mutex.Unlock()
 				}
+				mutex.Unlock()
 
-				acc.AddError(queryError)
-			}(pool, q, dsn)
+				if !encrypted {
+					acc.AddError(queryError)
+				}
+			}(i, pool, q, dsn)
 		}
 	}
 
 	wg.Wait()
 
+	if s.CircuitBreakerThreshold > 0 {
+		for i, breaker := range s.breakers {
+			if attempted[i] {
+				breaker.record(succeeded[i], now, s.CircuitBreakerThreshold, time.Duration(s.CircuitBreakerCooldown))
+			}
+		}
+	}
+
 	if s.HealthMetric {
 		s.accHealth(healthMetrics, acc)
 	}
@@ -204,6 +332,23 @@ func (s *SQLServer) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// record updates the breaker with the outcome of querying the instance. An
+// instance is considered failed if none of its queries succeeded. Once the
+// threshold is reached, every further failure (e.g. the probe after the
+// cooldown) opens the breaker again.
+func (b *circuitBreaker) record(success bool, now time.Time, threshold int, cooldown time.Duration) {
+	if success {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+
+	b.failures++
+	if b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+	}
+}
+
 // Stop cleanup server connection pools
 func (s *SQLServer) Stop() {
 	for _, pool := range s.pools {
@@ -212,9 +357,96 @@ func (s *SQLServer) Stop() {
 }
 
 func (s *SQLServer) initQueries() error {
-	s.queries = make(mapQuery)
-	queries := s.queries
 	s.Log.Infof("Config: database_type: %s , query_version:%d , azuredb: %t", s.DatabaseType, s.QueryVersion, s.AzureDB)
+	s.queries = s.definedQueries(s.DatabaseType)
+	queries := s.queries
+
+	filterQueries, err := filter.NewIncludeExcludeFilter(s.IncludeQuery, s.ExcludeQuery)
+	if err != nil {
+		return err
+	}
+
+	// Remove the optional queries not explicitly included
+	filterIncluded, err := filter.Compile(s.IncludeQuery)
+	if err != nil {
+		return err
+	}
+	for _, name := range optionalQueries {
+		if filterIncluded == nil || !filterIncluded.Match(name) {
+			delete(queries, name)
+		}
+	}
+
+	for query := range queries {
+		if !filterQueries.Match(query) {
+			delete(queries, query)
+		}
+	}
+
+	queryList := make([]string, 0, len(queries))
+	for query := range queries {
+		queryList = append(queryList, query)
+	}
+	s.Log.Infof("Config: Effective Queries: %#v\n", queryList)
+
+	return nil
+}
+
+// checkQueryNames returns an error for included queries not available for the
+// database type. Excluded queries may be available for any database type, to
+// allow sharing the exclusions across instances of different types.
+func (s *SQLServer) checkQueryNames() error {
+	available := s.definedQueries(s.DatabaseType)
+	for _, pattern := range s.IncludeQuery {
+		found, err := matchesQuery(pattern, available)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("included query %q does not match any query available for database type %q", pattern, s.DatabaseType)
+		}
+	}
+
+	known := make(mapQuery)
+	for _, databaseType := range []string{typeAzureSQLDB, typeAzureSQLManagedInstance, typeAzureSQLPool, typeAzureArcSQLManagedInstance, typeSQLServer} {
+		maps.Copy(known, s.definedQueries(databaseType))
+	}
+	// The legacy queries depend on the query version and AzureDB settings, so
+	// collect all variants independent of the settings of this instance
+	for _, version := range []int{1, 2} {
+		legacy := &SQLServer{QueryVersion: version, AzureDB: true}
+		maps.Copy(known, legacy.definedQueries(""))
+	}
+	for _, pattern := range s.ExcludeQuery {
+		found, err := matchesQuery(pattern, known)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("excluded query %q does not match any known query", pattern)
+		}
+	}
+
+	return nil
+}
+
+// matchesQuery checks if the given pattern matches any of the queries
+func matchesQuery(pattern string, queries mapQuery) (bool, error) {
+	f, err := filter.Compile([]string{pattern})
+	if err != nil {
+		return false, err
+	}
+	for name := range queries {
+		if f.Match(name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// definedQueries returns the queries available for the given database type
+func (s *SQLServer) definedQueries(databaseType string) mapQuery {
+	queries := make(mapQuery)
 
 	// To prevent query definition conflicts
 	// Constant definitions for type "AzureSQLDB" start with sqlAzureDB
@@ -222,7 +454,7 @@ func (s *SQLServer) initQueries() error {
 	// Constant definitions for type "AzureSQLPool" start with sqlAzurePool
 	// Constant definitions for type "AzureArcSQLManagedInstance" start with sqlAzureArcMI
 	// Constant definitions for type "SQLServer" start with sqlServer
-	if s.DatabaseType == typeAzureSQLDB {
+	if databaseType == typeAzureSQLDB {
 		queries["AzureSQLDBResourceStats"] = query{ScriptName: "AzureSQLDBResourceStats", Script: sqlAzureDBResourceStats, ResultByRow: false}
 		queries["AzureSQLDBResourceGovernance"] = query{ScriptName: "AzureSQLDBResourceGovernance", Script: sqlAzureDBResourceGovernance, ResultByRow: false}
 		queries["AzureSQLDBWaitStats"] = query{ScriptName: "AzureSQLDBWaitStats", Script: sqlAzureDBWaitStats, ResultByRow: false}
@@ -233,7 +465,8 @@ func (s *SQLServer) initQueries() error {
 		queries["AzureSQLDBPerformanceCounters"] = query{ScriptName: "AzureSQLDBPerformanceCounters", Script: sqlAzureDBPerformanceCounters, ResultByRow: false}
 		queries["AzureSQLDBRequests"] = query{ScriptName: "AzureSQLDBRequests", Script: sqlAzureDBRequests, ResultByRow: false}
 		queries["AzureSQLDBSchedulers"] = query{ScriptName: "AzureSQLDBSchedulers", Script: sqlAzureDBSchedulers, ResultByRow: false}
-	} else if s.DatabaseType == typeAzureSQLManagedInstance {
+		queries["SQLServerSchedulerDetail"] = query{ScriptName: "SQLServerSchedulerDetail", Script: sqlServerSchedulerDetail, ResultByRow: false}
+	} else if databaseType == typeAzureSQLManagedInstance {
 		queries["AzureSQLMIResourceStats"] = query{ScriptName: "AzureSQLMIResourceStats", Script: sqlAzureMIResourceStats, ResultByRow: false}
 		queries["AzureSQLMIResourceGovernance"] = query{ScriptName: "AzureSQLMIResourceGovernance", Script: sqlAzureMIResourceGovernance, ResultByRow: false}
 		queries["AzureSQLMIDatabaseIO"] = query{ScriptName: "AzureSQLMIDatabaseIO", Script: sqlAzureMIDatabaseIO, ResultByRow: false}
@@ -243,7 +476,8 @@ func (s *SQLServer) initQueries() error {
 		queries["AzureSQLMIPerformanceCounters"] = query{ScriptName: "AzureSQLMIPerformanceCounters", Script: sqlAzureMIPerformanceCounters, ResultByRow: false}
 		queries["AzureSQLMIRequests"] = query{ScriptName: "AzureSQLMIRequests", Script: sqlAzureMIRequests, ResultByRow: false}
 		queries["AzureSQLMISchedulers"] = query{ScriptName: "AzureSQLMISchedulers", Script: sqlAzureMISchedulers, ResultByRow: false}
-	} else if s.DatabaseType == typeAzureSQLPool {
+		queries["SQLServerSchedulerDetail"] = query{ScriptName: "SQLServerSchedulerDetail", Script: sqlServerSchedulerDetail, ResultByRow: false}
+	} else if databaseType == typeAzureSQLPool {
 		queries["AzureSQLPoolResourceStats"] = query{ScriptName: "AzureSQLPoolResourceStats", Script: sqlAzurePoolResourceStats, ResultByRow: false}
 		queries["AzureSQLPoolResourceGovernance"] =
 			query{ScriptName: "AzureSQLPoolResourceGovernance", Script: sqlAzurePoolResourceGovernance, ResultByRow: false}
@@ -253,7 +487,7 @@ func (s *SQLServer) initQueries() error {
 		queries["AzureSQLPoolPerformanceCounters"] =
 			query{ScriptName: "AzureSQLPoolPerformanceCounters", Script: sqlAzurePoolPerformanceCounters, ResultByRow: false}
 		queries["AzureSQLPoolSchedulers"] = query{ScriptName: "AzureSQLPoolSchedulers", Script: sqlAzurePoolSchedulers, ResultByRow: false}
-	} else if s.DatabaseType == typeAzureArcSQLManagedInstance {
+	} else if databaseType == typeAzureArcSQLManagedInstance {
 		queries["AzureArcSQLMIDatabaseIO"] = query{ScriptName: "AzureArcSQLMIDatabaseIO", Script: sqlAzureArcMIDatabaseIO, ResultByRow: false}
 		queries["AzureArcSQLMIServerProperties"] = query{ScriptName: "AzureArcSQLMIServerProperties", Script: sqlAzureArcMIProperties, ResultByRow: false}
 		queries["AzureArcSQLMIOsWaitstats"] = query{ScriptName: "AzureArcSQLMIOsWaitstats", Script: sqlAzureArcMIOsWaitStats, ResultByRow: false}
@@ -262,7 +496,7 @@ func (s *SQLServer) initQueries() error {
 			query{ScriptName: "AzureArcSQLMIPerformanceCounters", Script: sqlAzureArcMIPerformanceCounters, ResultByRow: false}
 		queries["AzureArcSQLMIRequests"] = query{ScriptName: "AzureArcSQLMIRequests", Script: sqlAzureArcMIRequests, ResultByRow: false}
 		queries["AzureArcSQLMISchedulers"] = query{ScriptName: "AzureArcSQLMISchedulers", Script: sqlAzureArcMISchedulers, ResultByRow: false}
-	} else if s.DatabaseType == typeSQLServer { // These are still V2 queries and have not been refactored yet.
+	} else if databaseType == typeSQLServer { // These are still V2 queries and have not been refactored yet.
 		queries["SQLServerPerformanceCounters"] = query{ScriptName: "SQLServerPerformanceCounters", Script: sqlServerPerformanceCounters, ResultByRow: false}
 		queries["SQLServerWaitStatsCategorized"] = query{ScriptName: "SQLServerWaitStatsCategorized", Script: sqlServerWaitStatsCategorized, ResultByRow: false}
 		queries["SQLServerDatabaseIO"] = query{ScriptName: "SQLServerDatabaseIO", Script: sqlServerDatabaseIO, ResultByRow: false}
@@ -279,6 +513,8 @@ func (s *SQLServer) initQueries() error {
 		queries["SQLServerRecentBackups"] = query{ScriptName: "SQLServerRecentBackups", Script: sqlServerRecentBackups, ResultByRow: false}
 		queries["SQLServerPersistentVersionStore"] =
 			query{ScriptName: "SQLServerPersistentVersionStore", Script: sqlServerPersistentVersionStore, ResultByRow: false}
+		queries["SQLServerQueryStore"] = query{ScriptName: "SQLServerQueryStore", Script: sqlServerQueryStore, ResultByRow: false}
+		queries["SQLServerSchedulerDetail"] = query{ScriptName: "SQLServerSchedulerDetail", Script: sqlServerSchedulerDetail, ResultByRow: false}
 	} else {
 		// If this is an AzureDB instance, grab some extra metrics
 		if s.AzureDB {
@@ -297,8 +533,18 @@ func (s *SQLServer) initQueries() error {
 			queries["VolumeSpace"] = query{ScriptName: "VolumeSpace", Script: sqlServerVolumeSpaceV2, ResultByRow: false}
 			queries["Cpu"] = query{ScriptName: "Cpu", Script: sqlServerCPUV2, ResultByRow: false}
 		} else {
-			queries["PerformanceCounters"] = query{ScriptName: "PerformanceCounters", Script: sqlPerformanceCounters, ResultByRow: true}
-			queries["WaitStatsCategorized"] = query{ScriptName: "WaitStatsCategorized", Script: sqlWaitStatsCategorized, ResultByRow: false}
+			performanceCounters, waitStatsCategorized := sqlPerformanceCounters, sqlWaitStatsCategorized
+			if s.WaitStatsSampling == "snapshot" {
+				performanceCounters, waitStatsCategorized = sqlPerformanceCountersSnapshot, sqlWaitStatsCategorizedSnapshot
+			}
+			if s.WaitStatsDetail {
+				waitStatsCategorized = sqlWaitStatsDetail
+				if s.WaitStatsSampling == "snapshot" {
+					waitStatsCategorized = sqlWaitStatsDetailSnapshot
+				}
+			}
+			queries["PerformanceCounters"] = query{ScriptName: "PerformanceCounters", Script: performanceCounters, ResultByRow: true}
+			queries["WaitStatsCategorized"] = query{ScriptName: "WaitStatsCategorized", Script: waitStatsCategorized, ResultByRow: false}
 			queries["CPUHistory"] = query{ScriptName: "CPUHistory", Script: sqlCPUHistory, ResultByRow: false}
 			queries["DatabaseIO"] = query{ScriptName: "DatabaseIO", Script: sqlDatabaseIO, ResultByRow: false}
 			queries["DatabaseSize"] = query{ScriptName: "DatabaseSize", Script: sqlDatabaseSize, ResultByRow: false}
@@ -310,24 +556,7 @@ func (s *SQLServer) initQueries() error {
 		}
 	}
 
-	filterQueries, err := filter.NewIncludeExcludeFilter(s.IncludeQuery, s.ExcludeQuery)
-	if err != nil {
-		return err
-	}
-
-	for query := range queries {
-		if !filterQueries.Match(query) {
-			delete(queries, query)
-		}
-	}
-
-	queryList := make([]string, 0, len(queries))
-	for query := range queries {
-		queryList = append(queryList, query)
-	}
-	s.Log.Infof("Config: Effective Queries: %#v\n", queryList)
-
-	return nil
+	return queries
 }
 
 func (s *SQLServer) gatherServer(pool *sql.DB, query query, acc telegraf.Accumulator, connectionString string) error {
@@ -343,6 +572,14 @@ func (s *SQLServer) gatherServer(pool *sql.DB, query query, acc telegraf.Accumul
 	if err != nil {
 		serverName, databaseName := getConnectionIdentifiers(connectionString)
 
+		// Columns protected by Always Encrypted cannot be accessed, so do not
+		// report an error but let the user know what is missing
+		if columns, ok := encryptedColumns(err); ok {
+			s.Log.Warnf("Query %s for server: %s and database: %s returned no data due to encrypted columns %v: %v",
+				query.ScriptName, serverName, databaseName, columns, err)
+			return fmt.Errorf("%w %v", errEncryptedColumns, columns)
+		}
+
 		// Error msg based on the format in SSMS. SQLErrorClass() is another term for severity/level: http://msdn.microsoft.com/en-us/library/dd304156.aspx
 		var sqlErr mssql.Error
 		if errors.As(err, &sqlErr) {
@@ -367,9 +604,51 @@ func (s *SQLServer) gatherServer(pool *sql.DB, query query, acc telegraf.Accumul
 			return err
 		}
 	}
+
+	// Keep the rows gathered so far if the remaining rows cannot be decrypted
+	if columns, ok := encryptedColumns(rows.Err()); ok {
+		serverName, databaseName := getConnectionIdentifiers(connectionString)
+		s.Log.Warnf("Query %s for server: %s and database: %s returned partial data due to encrypted columns %v: %v",
+			query.ScriptName, serverName, databaseName, columns, rows.Err())
+		return fmt.Errorf("%w %v", errEncryptedColumns, columns)
+	}
 	return rows.Err()
 }
 
+// encryptedColumns checks if the error is caused by accessing columns
+// protected by Always Encrypted and returns the affected columns if those
+// are mentioned in the error message
+func encryptedColumns(err error) ([]string, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	msg := strings.ToLower(err.Error())
+	var sqlErr mssql.Error
+	if errors.As(err, &sqlErr) {
+		switch sqlErr.SQLErrorNumber() {
+		case 33299: // Encryption scheme mismatch for columns/variables
+		case 206, 402: // Operand type clash or incompatible data types
+			if !strings.Contains(msg, "encrypted") {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	} else if !strings.Contains(msg, "column encryption") {
+		return nil, false
+	}
+
+	// Collect the quoted column names, ignoring parameters
+	var columns []string
+	for _, match := range quotedNameRe.FindAllStringSubmatch(err.Error(), -1) {
+		if !strings.HasPrefix(match[1], "@") {
+			columns = append(columns, match[1])
+		}
+	}
+	return columns, true
+}
+
 func (s *SQLServer) accRow(query query, acc telegraf.Accumulator, row scanner) error {
 	var fields = make(map[string]interface{})
 
@@ -407,6 +686,9 @@ func (s *SQLServer) accRow(query query, acc telegraf.Accumulator, row scanner) e
 	if s.DatabaseType != "" {
 		tags["measurement_db_type"] = s.DatabaseType
 	}
+	for k, v := range query.Tags {
+		tags[k] = v
+	}
 
 	if query.ResultByRow {
 		// add measurement to Accumulator
@@ -416,9 +698,13 @@ func (s *SQLServer) accRow(query query, acc telegraf.Accumulator, row scanner) e
 	} else {
 		// values
 		for header, val := range columnMap {
-			if _, ok := (*val).(string); !ok {
-				fields[header] = *val
+			if _, ok := (*val).(string); ok {
+				continue
 			}
+			if s.fieldFilter != nil && !s.fieldFilter.Match(header) {
+				continue
+			}
+			fields[header] = *val
 		}
 		// add fields to Accumulator
 		acc.AddFields(measurement, fields, tags, time.Now())
@@ -426,6 +712,36 @@ func (s *SQLServer) accRow(query query, acc telegraf.Accumulator, row scanner) e
 	return nil
 }
 
+// queryVersionTags returns the version and edition tags of the instance
+func (s *SQLServer) queryVersionTags(pool *sql.DB) (map[string]string, error) {
+	ctx := context.Background()
+	if s.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.QueryTimeout))
+		defer cancel()
+	}
+	return scanVersionTags(pool.QueryRowContext(ctx, sqlServerVersion))
+}
+
+// scanVersionTags reads the version and edition of the instance from the
+// result of the version query
+func scanVersionTags(row scanner) (map[string]string, error) {
+	var version, edition interface{}
+	if err := row.Scan(&version, &edition); err != nil {
+		return nil, err
+	}
+
+	// The properties are NULL if not available for the instance
+	tags := make(map[string]string, 2)
+	if v, ok := version.(string); ok {
+		tags["sql_version"] = v
+	}
+	if v, ok := edition.(string); ok {
+		tags["sql_edition"] = v
+	}
+	return tags, nil
+}
+
 // gatherHealth stores info about any query errors in the healthMetrics map
 func gatherHealth(healthMetrics map[string]*healthMetric, serv string, queryError error) {
 	if healthMetrics[serv] == nil {
@@ -1480,3 +1480,61 @@ IF @MajorMinorVersion >= 1500 BEGIN
 	    and d.is_accelerated_database_recovery_on = 1
 END;
 `
+
+// Collects the top queries by total duration from `sys.query_store_runtime_stats` for the current database.
+// Query Store was added in SQL Server 2016, no rows are returned if it is disabled for the database.
+const sqlServerQueryStore string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END;
+
+DECLARE
+	@MajorMinorVersion AS int = CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),4) AS int)*100 + CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),3) AS int)
+
+IF @MajorMinorVersion < 1300
+	RETURN;
+
+IF NOT EXISTS (SELECT 1 FROM sys.database_query_store_options WHERE [actual_state_desc] IN ('READ_ONLY','READ_WRITE'))
+	RETURN;
+
+SELECT TOP(25)
+	'sqlserver_query_store' AS [measurement]
+	,REPLACE(@@SERVERNAME,'\',':') AS [sql_instance]
+	,DB_NAME() AS [database_name]
+	,CAST(p.[query_id] AS nvarchar(20)) AS [query_id]
+	,SUM(rs.[count_executions]) AS [execution_count]
+	,SUM(rs.[avg_duration] * rs.[count_executions]) AS [total_duration_us]
+	,MAX(rs.[max_duration]) AS [max_duration_us]
+	,SUM(rs.[avg_cpu_time] * rs.[count_executions]) AS [total_cpu_time_us]
+	,SUM(rs.[avg_logical_io_reads] * rs.[count_executions]) AS [total_logical_io_reads]
+	,SUM(rs.[avg_physical_io_reads] * rs.[count_executions]) AS [total_physical_io_reads]
+FROM sys.query_store_runtime_stats AS rs
+INNER JOIN sys.query_store_plan AS p
+	ON p.[plan_id] = rs.[plan_id]
+GROUP BY p.[query_id]
+ORDER BY [total_duration_us] DESC
+`
+
+// Collects the task and worker counts of every online scheduler from `sys.dm_os_schedulers` including
+// the NUMA node. The DMV is also available in Azure SQL Database and Managed Instance, no ring buffers used.
+const sqlServerSchedulerDetail string = `
+SET DEADLOCK_PRIORITY -10;
+
+SELECT
+	'sqlserver_scheduler_detail' AS [measurement]
+	,REPLACE(@@SERVERNAME,'\',':') AS [sql_instance]
+	,CAST(s.[scheduler_id] AS nvarchar(10)) AS [scheduler_id]
+	,CAST(s.[parent_node_id] AS nvarchar(10)) AS [parent_node_id]
+	,CAST(s.[cpu_id] AS nvarchar(10)) AS [cpu_id]
+	,s.[runnable_tasks_count]
+	,s.[current_tasks_count]
+	,s.[active_workers_count]
+	,s.[current_workers_count]
+	,s.[work_queue_count]
+	,s.[pending_disk_io_count]
+FROM sys.dm_os_schedulers AS s
+WHERE s.[status] = 'VISIBLE ONLINE'
+`
@@ -8,10 +8,13 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -56,11 +59,17 @@ var (
 	}
 )
 
+var (
+	errBinaryNotFound    = errors.New("binary not found")
+	errNoParseableOutput = errors.New("no parseable statistics in output")
+)
+
 type runner func(cmdName string, useSudo bool, args []string, timeout config.Duration) (*bytes.Buffer, error)
 
 // Varnish is used to store configuration values
 type Varnish struct {
 	Stats         []string
+	StatsFile     string `toml:"stats_file"`
 	Binary        string
 	BinaryArgs    []string
 	AdmBinary     string
@@ -71,14 +80,28 @@ type Varnish struct {
 	Regexps       []string
 	MetricVersion int
 
+	MeasurementPrefix   string          `toml:"measurement_prefix"`
+	KeepInactiveVCL     bool            `toml:"keep_inactive_vcl"`
+	VCLTag              string          `toml:"vcl_tag"`
+	WarnMissingCounters bool            `toml:"warn_missing_counters"`
+	Log                 telegraf.Logger `toml:"-"`
+
 	filter          filter.Filter
 	run             runner
 	admRun          runner
 	regexpsCompiled []*regexp.Regexp
+
+	// counters seen in the previous and current gather
+	seenCounters    map[string]bool
+	currentCounters map[string]bool
 }
 
 // Shell out to varnish cli and return the output
 func varnishRunner(cmdName string, useSudo bool, cmdArgs []string, timeout config.Duration) (*bytes.Buffer, error) {
+	if _, err := exec.LookPath(cmdName); err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", errBinaryNotFound, cmdName, err)
+	}
+
 	cmd := exec.Command(cmdName, cmdArgs...)
 
 	if useSudo {
@@ -103,6 +126,13 @@ func (*Varnish) SampleConfig() string {
 }
 
 func (s *Varnish) Init() error {
+	if s.MeasurementPrefix == "" {
+		s.MeasurementPrefix = measurementNamespace
+	}
+	if s.VCLTag == "" {
+		s.VCLTag = "vcl"
+	}
+
 	customRegexps := make([]*regexp.Regexp, 0, len(s.Regexps))
 	for _, re := range s.Regexps {
 		compiled, err := regexp.Compile(re)
@@ -140,15 +170,29 @@ func (s *Varnish) Gather(acc telegraf.Accumulator) error {
 
 	admArgs, statsArgs := s.prepareCmdArgs()
 
-	statOut, err := s.run(s.Binary, s.UseSudo, statsArgs, s.Timeout)
-	if err != nil {
-		return fmt.Errorf("error gathering metrics: %w", err)
+	// read the stats from file if given instead of running varnishstat
+	var statOut *bytes.Buffer
+	var err error
+	if s.StatsFile != "" {
+		buf, err := os.ReadFile(s.StatsFile)
+		if err != nil {
+			return fmt.Errorf("error reading stats file: %w", err)
+		}
+		statOut = bytes.NewBuffer(buf)
+	} else {
+		statOut, err = s.run(s.Binary, s.UseSudo, statsArgs, s.Timeout)
+		if err != nil {
+			return fmt.Errorf("error gathering metrics: %w", err)
+		}
 	}
 
+	if s.WarnMissingCounters {
+		s.currentCounters = make(map[string]bool, len(s.seenCounters))
+	}
 	if s.MetricVersion == 2 {
 		// run varnishadm to get active vcl
 		var activeVcl = "boot"
-		if s.admRun != nil {
+		if s.admRun != nil && s.StatsFile == "" {
 			admOut, err := s.admRun(s.AdmBinary, s.UseSudo, admArgs, s.Timeout)
 			if err != nil {
 				return fmt.Errorf("error gathering metrics: %w", err)
@@ -158,9 +202,34 @@ func (s *Varnish) Gather(acc telegraf.Accumulator) error {
 				return fmt.Errorf("error gathering metrics: %w", err)
 			}
 		}
-		return s.processMetricsV2(activeVcl, acc, statOut)
+		err = s.processMetricsV2(activeVcl, acc, statOut)
+	} else {
+		err = s.processMetricsV1(acc, statOut)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.WarnMissingCounters {
+		s.checkMissingCounters()
+	}
+	return nil
+}
+
+// Warn about counters seen in the previous gather but not in the current one,
+// e.g. due to counters being renamed after an upgrade or VCL change
+func (s *Varnish) checkMissingCounters() {
+	missing := make([]string, 0)
+	for name := range s.seenCounters {
+		if !s.currentCounters[name] {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+	for _, name := range missing {
+		s.Log.Warnf("Counter %q disappeared since the last gather", name)
 	}
-	return s.processMetricsV1(acc, statOut)
+	s.seenCounters = s.currentCounters
 }
 
 // Prepare varnish cli tools arguments
@@ -193,6 +262,7 @@ func (s *Varnish) prepareCmdArgs() ([]string, []string) {
 
 func (s *Varnish) processMetricsV1(acc telegraf.Accumulator, out *bytes.Buffer) error {
 	sectionMap := make(map[string]map[string]interface{})
+	var parseable bool
 	scanner := bufio.NewScanner(out)
 	for scanner.Scan() {
 		cols := strings.Fields(scanner.Text())
@@ -202,6 +272,7 @@ func (s *Varnish) processMetricsV1(acc telegraf.Accumulator, out *bytes.Buffer)
 		if !strings.Contains(cols[0], ".") {
 			continue
 		}
+		parseable = true
 
 		stat := cols[0]
 		value := cols[1]
@@ -219,10 +290,14 @@ func (s *Varnish) processMetricsV1(acc telegraf.Accumulator, out *bytes.Buffer)
 			sectionMap[section] = make(map[string]interface{})
 		}
 
-		var err error
-		sectionMap[section][field], err = strconv.ParseUint(value, 10, 64)
+		v, err := strconv.ParseUint(value, 10, 64)
 		if err != nil {
-			acc.AddError(fmt.Errorf("expected a numeric value for %s = %v", stat, value))
+			acc.AddError(fmt.Errorf("expected a numeric value for %s = %v: %w", stat, value, err))
+			continue
+		}
+		sectionMap[section][field] = v
+		if s.WarnMissingCounters {
+			s.currentCounters[stat] = true
 		}
 	}
 
@@ -236,6 +311,13 @@ func (s *Varnish) processMetricsV1(acc telegraf.Accumulator, out *bytes.Buffer)
 
 		acc.AddFields("varnish", fields, tags)
 	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading output failed: %w", err)
+	}
+	if !parseable {
+		return errNoParseableOutput
+	}
 	return nil
 }
 
@@ -245,7 +327,7 @@ func (s *Varnish) processMetricsV2(activeVcl string, acc telegraf.Accumulator, o
 	dec := json.NewDecoder(out)
 	dec.UseNumber()
 	if err := dec.Decode(&rootJSON); err != nil {
-		return err
+		return fmt.Errorf("%w: %w", errNoParseableOutput, err)
 	}
 	countersJSON := getCountersJSON(rootJSON)
 	timestamp := time.Now()
@@ -290,11 +372,20 @@ func (s *Varnish) processMetricsV2(activeVcl string, acc telegraf.Accumulator, o
 		}
 
 		metric := s.parseMetricV2(fieldName)
-		if metric.vclName != "" && activeVcl != "" && metric.vclName != activeVcl {
+		if s.KeepInactiveVCL {
+			// keep all vcls distinguished by tag
+			if metric.vclName != "" {
+				metric.tags[s.VCLTag] = metric.vclName
+			}
+		} else if metric.vclName != "" && activeVcl != "" && metric.vclName != activeVcl {
 			// skip not active vcl
 			continue
 		}
 
+		if s.WarnMissingCounters {
+			s.currentCounters[metric.key()] = true
+		}
+
 		fields := make(map[string]interface{})
 		fields[metric.fieldName] = metricValue
 		switch flag {
@@ -354,7 +445,7 @@ func getCountersJSON(rootJSON map[string]interface{}) map[string]interface{} {
 
 // converts varnish metrics name into field and list of tags
 func (s *Varnish) parseMetricV2(name string) (metric varnishMetric) {
-	metric.measurement = measurementNamespace
+	metric.measurement = s.MeasurementPrefix
 	if strings.Count(name, ".") == 0 {
 		return metric
 	}
@@ -395,6 +486,16 @@ type varnishMetric struct {
 	vclName     string
 }
 
+// key identifies the metric independent of the VCL it belongs to
+func (m *varnishMetric) key() string {
+	tags := make([]string, 0, len(m.tags))
+	for k, v := range m.tags {
+		tags = append(tags, k+"="+v)
+	}
+	slices.Sort(tags)
+	return m.measurement + "," + strings.Join(tags, ",") + " " + m.fieldName
+}
+
 func init() {
 	inputs.Add("varnish", func() telegraf.Input {
 		return &Varnish{
@@ -4,14 +4,20 @@ package varnish
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -609,6 +615,7 @@ func TestJsonTypes(t *testing.T) {
 		Stats:           []string{"*"},
 		MetricVersion:   2,
 	}
+	require.NoError(t, v.Init())
 	require.NoError(t, v.Gather(acc))
 	require.Equal(t, len(exp), len(acc.Metrics))
 	for _, metric := range acc.Metrics {
@@ -632,3 +639,211 @@ func TestVarnishAdmJson(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "reload_20210723_091821_2056185", activeVcl)
 }
+
+func TestWarnMissingCounters(t *testing.T) {
+	outputs := []string{
+		"MAIN.cache_hit 10 0.00 Cache hits\nMAIN.cache_miss 5 0.00 Cache misses\n",
+		"MAIN.cache_hit 12 0.00 Cache hits\n",
+	}
+
+	var logger testutil.CaptureLogger
+	var call int
+	v := &Varnish{
+		run: func(string, bool, []string, config.Duration) (*bytes.Buffer, error) {
+			out := outputs[call]
+			call++
+			return bytes.NewBufferString(out), nil
+		},
+		Stats:               []string{"*"},
+		WarnMissingCounters: true,
+		Log:                 &logger,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, v.Gather(&acc))
+	require.Empty(t, logger.Warnings())
+
+	require.NoError(t, v.Gather(&acc))
+	warnings := logger.Warnings()
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], `Counter "MAIN.cache_miss" disappeared since the last gather`)
+}
+
+func TestNonNumericValue(t *testing.T) {
+	v := &Varnish{
+		run:   fakeVarnishRunner("MAIN.cache_hit abc 0.00 Cache hits\nMAIN.cache_miss 5 0.00 Cache misses\n"),
+		Stats: []string{"*"},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, v.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorIs(t, acc.Errors[0], strconv.ErrSyntax)
+
+	// Only the valid counter is reported
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, map[string]interface{}{"cache_miss": uint64(5)}, acc.Metrics[0].Fields)
+}
+
+func TestNoParseableOutput(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		t.Run(strconv.Itoa(version), func(t *testing.T) {
+			v := &Varnish{
+				run:           fakeVarnishRunner("varnishstat: command failed\n"),
+				Stats:         []string{"*"},
+				MetricVersion: version,
+			}
+
+			var acc testutil.Accumulator
+			require.ErrorIs(t, v.Gather(&acc), errNoParseableOutput)
+		})
+	}
+}
+
+func TestBinaryNotFound(t *testing.T) {
+	v := &Varnish{
+		run:    varnishRunner,
+		Binary: filepath.Join(t.TempDir(), "varnishstat"),
+		Stats:  []string{"*"},
+	}
+
+	var acc testutil.Accumulator
+	err := v.Gather(&acc)
+	require.ErrorIs(t, err, errBinaryNotFound)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestKeepInactiveVCL(t *testing.T) {
+	output, err := os.ReadFile("test_data/varnish_two_vcls.json")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		keep     bool
+		expected []telegraf.Metric
+	}{
+		{
+			name: "active only",
+			expected: []telegraf.Metric{
+				metric.New(
+					"varnish",
+					map[string]string{"section": "MGT"},
+					map[string]interface{}{"uptime": int64(238359)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"varnish",
+					map[string]string{"section": "VBE", "backend": "default"},
+					map[string]interface{}{"bereq_hdrbytes": int64(1024)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+		},
+		{
+			name: "keep all",
+			keep: true,
+			expected: []telegraf.Metric{
+				metric.New(
+					"varnish",
+					map[string]string{"section": "MGT"},
+					map[string]interface{}{"uptime": int64(238359)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"varnish",
+					map[string]string{"section": "VBE", "backend": "default", "vcl_name": "boot"},
+					map[string]interface{}{"bereq_hdrbytes": int64(1024)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"varnish",
+					map[string]string{"section": "VBE", "backend": "default", "vcl_name": "label_api"},
+					map[string]interface{}{"bereq_hdrbytes": int64(2048)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Varnish{
+				regexpsCompiled: defaultRegexps,
+				KeepInactiveVCL: tt.keep,
+				VCLTag:          "vcl_name",
+			}
+			require.NoError(t, v.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, v.processMetricsV2("boot", &acc, bytes.NewBuffer(output)))
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestStatsFile(t *testing.T) {
+	v := &Varnish{
+		run: func(string, bool, []string, config.Duration) (*bytes.Buffer, error) {
+			return nil, errors.New("runner must not be called")
+		},
+		admRun: func(string, bool, []string, config.Duration) (*bytes.Buffer, error) {
+			return nil, errors.New("runner must not be called")
+		},
+		regexpsCompiled: defaultRegexps,
+		StatsFile:       "test_data/varnish_two_vcls.json",
+		Stats:           []string{"MGT.*", "VBE.*"},
+		MetricVersion:   2,
+	}
+	require.NoError(t, v.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, v.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"varnish",
+			map[string]string{"section": "MGT"},
+			map[string]interface{}{"uptime": int64(238359)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"varnish",
+			map[string]string{"section": "VBE", "backend": "default"},
+			map[string]interface{}{"bereq_hdrbytes": int64(1024)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestMeasurementPrefix(t *testing.T) {
+	v := &Varnish{
+		run:               fakeVarnishRunner(`{"MAIN.cache_hit": {"flag": "c", "value": 42}}`),
+		regexpsCompiled:   defaultRegexps,
+		Stats:             []string{"*"},
+		MetricVersion:     2,
+		MeasurementPrefix: "cache",
+	}
+	require.NoError(t, v.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, v.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cache",
+			map[string]string{"section": "MAIN"},
+			map[string]interface{}{"cache_hit": int64(42)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
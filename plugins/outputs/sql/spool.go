@@ -0,0 +1,187 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+const (
+	spoolFileExtension   = ".lp"
+	spoolFailedExtension = ".failed"
+)
+
+// spool stores batches that failed to be written to the database as files
+// in influx line-protocol format, so they can be replayed later on
+type spool struct {
+	directory string
+	maxSize   int64
+	log       telegraf.Logger
+
+	size       int64
+	sequence   uint64
+	serializer *serializer.Serializer
+	parser     *influx.Parser
+}
+
+func newSpool(directory string, maxSize int64, log telegraf.Logger) (*spool, error) {
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return nil, fmt.Errorf("creating spool directory failed: %w", err)
+	}
+
+	s := &spool{
+		directory:  directory,
+		maxSize:    maxSize,
+		log:        log,
+		serializer: &serializer.Serializer{UintSupport: true},
+		parser:     &influx.Parser{},
+	}
+	if err := s.serializer.Init(); err != nil {
+		return nil, err
+	}
+	if err := s.parser.Init(); err != nil {
+		return nil, err
+	}
+
+	// Account for batches left over from a previous run
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range files {
+		info, err := os.Stat(fn)
+		if err != nil {
+			return nil, err
+		}
+		s.size += info.Size()
+	}
+
+	return s, nil
+}
+
+// files returns the spooled batches, oldest first
+func (s *spool) files() ([]string, error) {
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, fmt.Errorf("reading spool directory failed: %w", err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileExtension) {
+			continue
+		}
+		files = append(files, filepath.Join(s.directory, entry.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// store writes the given metrics to a new spool file as long as the size
+// limit is not exceeded
+func (s *spool) store(metrics []telegraf.Metric) error {
+	buf, err := s.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return fmt.Errorf("serializing metrics failed: %w", err)
+	}
+	if s.maxSize > 0 && s.size+int64(len(buf)) > s.maxSize {
+		return fmt.Errorf("spool size limit of %d bytes exceeded", s.maxSize)
+	}
+
+	s.sequence++
+	fn := filepath.Join(s.directory, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.sequence%1000000, spoolFileExtension))
+	if err := os.WriteFile(fn, buf, 0640); err != nil {
+		return fmt.Errorf("writing spool file failed: %w", err)
+	}
+	s.size += int64(len(buf))
+
+	return nil
+}
+
+// replay passes the spooled batches, oldest first, to the given write
+// function which returns the number of metrics successfully written. Files
+// are removed once all their metrics are written; on transient errors the
+// remaining metrics are kept in the file for the next attempt. Files failing
+// for other reasons, including unreadable or corrupt files, are moved out of
+// the way as they will never succeed.
+func (s *spool) replay(write func([]telegraf.Metric) (int, error), transient func(error) bool) error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range files {
+		buf, err := os.ReadFile(fn)
+		if err != nil {
+			var size int64
+			if info, serr := os.Stat(fn); serr == nil {
+				size = info.Size()
+			}
+			if qerr := s.quarantine(fn, size); qerr != nil {
+				return errors.Join(fmt.Errorf("reading spool file failed: %w", err), qerr)
+			}
+			s.log.Errorf("Reading spool file failed, moved it to %q: %v", fn+spoolFailedExtension, err)
+			continue
+		}
+		metrics, err := s.parser.Parse(buf)
+		if err != nil {
+			if qerr := s.quarantine(fn, int64(len(buf))); qerr != nil {
+				return errors.Join(fmt.Errorf("parsing spool file %q failed: %w", fn, err), qerr)
+			}
+			s.log.Errorf("Parsing spool file failed, moved it to %q: %v", fn+spoolFailedExtension, err)
+			continue
+		}
+
+		n, werr := write(metrics)
+		if werr == nil {
+			if err := os.Remove(fn); err != nil {
+				return fmt.Errorf("removing spool file failed: %w", err)
+			}
+			s.size -= int64(len(buf))
+			continue
+		}
+
+		// Keep the metrics not written yet for the next attempt
+		if n > 0 {
+			remaining, err := s.serializer.SerializeBatch(metrics[n:])
+			if err != nil {
+				return errors.Join(werr, fmt.Errorf("serializing metrics failed: %w", err))
+			}
+			if err := os.WriteFile(fn, remaining, 0640); err != nil {
+				return errors.Join(werr, fmt.Errorf("writing spool file failed: %w", err))
+			}
+			s.size -= int64(len(buf) - len(remaining))
+			buf = remaining
+		}
+		if transient(werr) {
+			return werr
+		}
+
+		// Quarantine the file to not block the following batches
+		if err := s.quarantine(fn, int64(len(buf))); err != nil {
+			return errors.Join(werr, err)
+		}
+		s.log.Errorf("Writing %d spooled metrics failed, moved them to %q: %v", len(metrics)-n, fn+spoolFailedExtension, werr)
+	}
+
+	return nil
+}
+
+// quarantine moves the given spool file of the given size out of the way so
+// it is not replayed again
+func (s *spool) quarantine(fn string, size int64) error {
+	if err := os.Rename(fn, fn+spoolFailedExtension); err != nil {
+		return fmt.Errorf("quarantining spool file failed: %w", err)
+	}
+	s.size -= size
+	return nil
+}
@@ -3,8 +3,11 @@ package sql
 
 import (
 	gosql "database/sql"
+	"database/sql/driver"
 	_ "embed"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
@@ -28,6 +31,9 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// metadataColumns are the columns of the table describing the metric schema
+var metadataColumns = []string{"table_name", "column_name", "column_type", "origin"}
+
 type ConvertStruct struct {
 	Integer         string `toml:"integer"`
 	Real            string `toml:"real"`
@@ -45,7 +51,11 @@ type SQL struct {
 	TimestampColumn       string          `toml:"timestamp_column"`
 	TableTemplate         string          `toml:"table_template"`
 	TableExistsTemplate   string          `toml:"table_exists_template"`
+	TableUpdateTemplate   string          `toml:"table_update_template"`
 	InitSQL               string          `toml:"init_sql"`
+	MetadataTable         string          `toml:"metadata_table"`
+	UnifyNumericColumns   bool            `toml:"unify_numeric_columns"`
+	Timezone              string          `toml:"timezone"`
 	Convert               ConvertStruct   `toml:"convert"`
 	ConnectionMaxIdleTime config.Duration `toml:"connection_max_idle_time"`
 	ConnectionMaxLifetime config.Duration `toml:"connection_max_lifetime"`
@@ -53,14 +63,45 @@ type SQL struct {
 	ConnectionMaxOpen     int             `toml:"connection_max_open"`
 	Log                   telegraf.Logger `toml:"-"`
 
-	db     *gosql.DB
-	tables map[string]bool
+	SpoolDirectory string      `toml:"spool_directory"`
+	SpoolMaxSize   config.Size `toml:"spool_max_size"`
+
+	db       *gosql.DB
+	tables   map[string]map[string]bool
+	location *time.Location
+	spool    *spool
+}
+
+// column describes a column of a metric table
+type column struct {
+	name, datatype, origin string
 }
 
 func (*SQL) SampleConfig() string {
 	return sampleConfig
 }
 
+func (p *SQL) Init() error {
+	if p.Timezone == "" {
+		p.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", p.Timezone, err)
+	}
+	p.location = loc
+
+	if p.SpoolDirectory != "" {
+		s, err := newSpool(p.SpoolDirectory, int64(p.SpoolMaxSize), p.Log)
+		if err != nil {
+			return err
+		}
+		p.spool = s
+	}
+
+	return nil
+}
+
 func (p *SQL) Connect() error {
 	dsn := p.DataSourceName
 	if p.Driver == "clickhouse" {
@@ -90,7 +131,20 @@ func (p *SQL) Connect() error {
 	}
 
 	p.db = db
-	p.tables = make(map[string]bool)
+	p.tables = make(map[string]map[string]bool)
+
+	if p.MetadataTable != "" && !p.tableExists(p.MetadataTable) {
+		if _, err := db.Exec(p.generateCreateMetadataTable()); err != nil {
+			return fmt.Errorf("creating metadata table failed: %w", err)
+		}
+	}
+
+	// Replay batches spooled during a previous outage
+	if p.spool != nil {
+		if err := p.spool.replay(p.writeMetrics, p.transientError); err != nil {
+			p.Log.Errorf("Replaying spooled metrics failed: %v", err)
+		}
+	}
 
 	return nil
 }
@@ -127,6 +181,13 @@ func sanitizeQuoted(in string) string {
 func (p *SQL) deriveDatatype(value interface{}) string {
 	var datatype string
 
+	if p.UnifyNumericColumns {
+		switch value.(type) {
+		case int64, uint64, float64:
+			return p.Convert.Real
+		}
+	}
+
 	switch value.(type) {
 	case int64:
 		datatype = p.Convert.Integer
@@ -151,6 +212,22 @@ func (p *SQL) deriveDatatype(value interface{}) string {
 	return datatype
 }
 
+// convertValue converts numeric field values to float if all numeric fields
+// are stored in columns of the same type
+func (p *SQL) convertValue(value interface{}) interface{} {
+	if !p.UnifyNumericColumns {
+		return value
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return value
+}
+
 func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
 	columns := make([]string, 0, len(metric.TagList())+len(metric.FieldList())+1)
 
@@ -176,22 +253,37 @@ func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
 	return query
 }
 
+func (p *SQL) generateCreateMetadataTable() string {
+	columns := make([]string, 0, len(metadataColumns))
+	for _, column := range metadataColumns {
+		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(column), p.Convert.Text))
+	}
+
+	query := p.TableTemplate
+	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(p.MetadataTable))
+	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(p.MetadataTable))
+	query = strings.ReplaceAll(query, "{COLUMNS}", strings.Join(columns, ","))
+
+	return query
+}
+
+// placeholder returns the parameter placeholder for the n-th (zero-based)
+// value of a statement
+func (p *SQL) placeholder(n int) string {
+	if p.Driver == "pgx" {
+		// Postgres uses $1 $2 $3 as placeholders
+		return fmt.Sprintf("$%d", n+1)
+	}
+	// Everything else uses ? ? ? as placeholders
+	return "?"
+}
+
 func (p *SQL) generateInsert(tablename string, columns []string) string {
 	placeholders := make([]string, 0, len(columns))
 	quotedColumns := make([]string, 0, len(columns))
-	for _, column := range columns {
+	for i, column := range columns {
 		quotedColumns = append(quotedColumns, quoteIdent(column))
-	}
-	if p.Driver == "pgx" {
-		// Postgres uses $1 $2 $3 as placeholders
-		for i := 0; i < len(columns); i++ {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
-		}
-	} else {
-		// Everything else uses ? ? ? as placeholders
-		for i := 0; i < len(columns); i++ {
-			placeholders = append(placeholders, "?")
-		}
+		placeholders = append(placeholders, p.placeholder(i))
 	}
 
 	return fmt.Sprintf("INSERT INTO %s (%s) VALUES(%s)",
@@ -200,6 +292,50 @@ func (p *SQL) generateInsert(tablename string, columns []string) string {
 		strings.Join(placeholders, ","))
 }
 
+// metricColumns returns the columns required to store the given metric
+func (p *SQL) metricColumns(metric telegraf.Metric) []column {
+	columns := make([]column, 0, len(metric.TagList())+len(metric.FieldList())+1)
+	if p.TimestampColumn != "" {
+		columns = append(columns, column{p.TimestampColumn, p.Convert.Timestamp, "timestamp"})
+	}
+	for _, tag := range metric.TagList() {
+		columns = append(columns, column{tag.Key, p.Convert.Text, "tag"})
+	}
+	for _, field := range metric.FieldList() {
+		columns = append(columns, column{field.Key, p.deriveDatatype(field.Value), "field"})
+	}
+	return columns
+}
+
+// updateMetadata records the given columns of the table in the metadata
+// table, replacing any previous entries of the same columns
+func (p *SQL) updateMetadata(tablename string, columns []column) error {
+	deleteStmt := fmt.Sprintf("DELETE FROM %s WHERE %s = %s AND %s = %s",
+		quoteIdent(p.MetadataTable),
+		quoteIdent(metadataColumns[0]), p.placeholder(0),
+		quoteIdent(metadataColumns[1]), p.placeholder(1))
+	insertStmt := p.generateInsert(p.MetadataTable, metadataColumns)
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin failed: %w", err)
+	}
+	for _, c := range columns {
+		if _, err := tx.Exec(deleteStmt, tablename, c.name); err != nil {
+			tx.Rollback() //nolint:errcheck // we already have an error to return
+			return fmt.Errorf("removing metadata of column %q failed: %w", c.name, err)
+		}
+		if _, err := tx.Exec(insertStmt, tablename, c.name, c.datatype, c.origin); err != nil {
+			tx.Rollback() //nolint:errcheck // we already have an error to return
+			return fmt.Errorf("inserting metadata of column %q failed: %w", c.name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
+}
+
 func (p *SQL) tableExists(tableName string) bool {
 	stmt := strings.ReplaceAll(p.TableExistsTemplate, "{TABLE}", quoteIdent(tableName))
 
@@ -207,28 +343,228 @@ func (p *SQL) tableExists(tableName string) bool {
 	return err == nil
 }
 
+// generateColumnsQuery returns the query listing the column names of a table
+// using the schema information of the database
+func (p *SQL) generateColumnsQuery() string {
+	switch p.Driver {
+	case "sqlite":
+		return "SELECT name FROM pragma_table_info(?)"
+	case "pgx":
+		return "SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1"
+	case "mysql":
+		return "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?"
+	case "mssql":
+		return "SELECT column_name FROM information_schema.columns WHERE table_schema = SCHEMA_NAME() AND table_name = ?"
+	case "clickhouse":
+		return "SELECT name FROM system.columns WHERE database = currentDatabase() AND table = ?"
+	}
+	return "SELECT column_name FROM information_schema.columns WHERE table_name = " + p.placeholder(0)
+}
+
+// tableColumns queries the names of the existing columns of the given table
+func (p *SQL) tableColumns(tablename string) (map[string]bool, error) {
+	rows, err := p.db.Query(p.generateColumnsQuery(), tablename)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+func (p *SQL) generateAddColumn(tablename string, c column) string {
+	query := p.TableUpdateTemplate
+	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(tablename))
+	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(tablename))
+	query = strings.ReplaceAll(query, "{COLUMN}", fmt.Sprintf("%s %s", quoteIdent(c.name), c.datatype))
+	return query
+}
+
+// updateTable adds the columns of the metric missing in the table. The
+// known columns are cached and the schema is only queried if the metric
+// contains columns not seen before.
+func (p *SQL) updateTable(metric telegraf.Metric) error {
+	tablename := metric.Name()
+	columns := p.metricColumns(metric)
+
+	missing := make([]column, 0, len(columns))
+	for _, c := range columns {
+		if !p.tables[tablename][c.name] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// Refresh the known columns as the schema might have been changed by
+	// others since we last checked
+	known, err := p.tableColumns(tablename)
+	if err != nil {
+		p.Log.Warnf("Querying columns of table %q failed, skipping schema update: %v", tablename, err)
+		known = make(map[string]bool, len(columns))
+		for _, c := range columns {
+			known[c.name] = true
+		}
+		p.tables[tablename] = known
+		return nil
+	}
+	p.tables[tablename] = known
+
+	missing = missing[:0]
+	for _, c := range columns {
+		if !known[c.name] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin failed: %w", err)
+	}
+	for _, c := range missing {
+		if _, err := tx.Exec(p.generateAddColumn(tablename, c)); err != nil {
+			tx.Rollback() //nolint:errcheck // we already have an error to handle
+			return p.checkConcurrentUpdate(tablename, missing, fmt.Errorf("adding column %q failed: %w", c.name, err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return p.checkConcurrentUpdate(tablename, missing, fmt.Errorf("commit failed: %w", err))
+	}
+
+	for _, c := range missing {
+		known[c.name] = true
+	}
+	if p.MetadataTable != "" {
+		return p.updateMetadata(tablename, missing)
+	}
+	return nil
+}
+
+// checkConcurrentUpdate ignores the given error of adding the columns if all
+// columns exist, e.g. because another writer added the columns concurrently
+// resulting in a duplicate-column error
+func (p *SQL) checkConcurrentUpdate(tablename string, columns []column, err error) error {
+	known, qerr := p.tableColumns(tablename)
+	if qerr != nil {
+		return err
+	}
+	p.tables[tablename] = known
+	for _, c := range columns {
+		if !known[c.name] {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *SQL) Write(metrics []telegraf.Metric) error {
+	if p.spool == nil {
+		_, err := p.writeMetrics(metrics)
+		return err
+	}
+
+	// Write the spooled batches first to keep the order of metrics. If the
+	// database is still unavailable add the current batch to the spool.
+	if err := p.spool.replay(p.writeMetrics, p.transientError); err != nil {
+		p.Log.Debugf("Replaying spooled metrics failed: %v", err)
+		if !p.transientError(err) {
+			return err
+		}
+		return p.spoolMetrics(metrics, err)
+	}
+
+	// Only spool batches that might succeed later on
+	n, err := p.writeMetrics(metrics)
+	if err != nil {
+		if !p.transientError(err) {
+			return err
+		}
+		return p.spoolMetrics(metrics[n:], err)
+	}
+	return nil
+}
+
+// transientError checks if the given write error is caused by the database
+// being unavailable. Other errors like type mismatches or constraint
+// violations will fail again when retrying the write.
+func (p *SQL) transientError(err error) bool {
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, gosql.ErrConnDone) || errors.As(err, &netErr) {
+		return true
+	}
+	return p.db.Ping() != nil
+}
+
+// spoolMetrics stores the metrics that could not be written to the
+// database in the spool, returning the original write error if this fails
+func (p *SQL) spoolMetrics(metrics []telegraf.Metric, werr error) error {
+	if err := p.spool.store(metrics); err != nil {
+		p.Log.Errorf("Spooling %d metrics failed: %v", len(metrics), err)
+		return werr
+	}
+	p.Log.Warnf("Writing to database failed, spooled %d metrics: %v", len(metrics), werr)
+	return nil
+}
+
+// writeMetrics inserts the given metrics and returns the number of metrics
+// written before an error occurred
+func (p *SQL) writeMetrics(metrics []telegraf.Metric) (int, error) {
 	var err error
 
-	for _, metric := range metrics {
+	for i, metric := range metrics {
 		tablename := metric.Name()
 
 		// create table if needed
-		if !p.tables[tablename] && !p.tableExists(tablename) {
+		if _, found := p.tables[tablename]; !found && !p.tableExists(tablename) {
 			createStmt := p.generateCreateTable(metric)
 			_, err := p.db.Exec(createStmt)
 			if err != nil {
-				return err
+				return i, err
 			}
+			columns := p.metricColumns(metric)
+			if p.MetadataTable != "" {
+				if err := p.updateMetadata(tablename, columns); err != nil {
+					return i, err
+				}
+			}
+			known := make(map[string]bool, len(columns))
+			for _, c := range columns {
+				known[c.name] = true
+			}
+			p.tables[tablename] = known
+		}
+
+		// add missing columns if needed
+		if p.TableUpdateTemplate != "" {
+			if err := p.updateTable(metric); err != nil {
+				return i, err
+			}
+		} else if _, found := p.tables[tablename]; !found {
+			p.tables[tablename] = make(map[string]bool)
 		}
-		p.tables[tablename] = true
 
 		var columns []string
 		var values []interface{}
 
 		if p.TimestampColumn != "" {
+			timestamp := metric.Time()
+			if p.location != nil {
+				timestamp = timestamp.In(p.location)
+			}
 			columns = append(columns, p.TimestampColumn)
-			values = append(values, metric.Time())
+			values = append(values, timestamp)
 		}
 
 		for column, value := range metric.Tags() {
@@ -238,7 +574,7 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 
 		for column, value := range metric.Fields() {
 			columns = append(columns, column)
-			values = append(values, value)
+			values = append(values, p.convertValue(value))
 		}
 
 		sql := p.generateInsert(tablename, columns)
@@ -248,30 +584,30 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 			// ClickHouse needs to batch inserts with prepared statements
 			tx, err := p.db.Begin()
 			if err != nil {
-				return fmt.Errorf("begin failed: %w", err)
+				return i, fmt.Errorf("begin failed: %w", err)
 			}
 			stmt, err := tx.Prepare(sql)
 			if err != nil {
-				return fmt.Errorf("prepare failed: %w", err)
+				return i, fmt.Errorf("prepare failed: %w", err)
 			}
 			defer stmt.Close() //nolint:revive,gocritic // done on purpose, closing will be executed properly
 
 			_, err = stmt.Exec(values...)
 			if err != nil {
-				return fmt.Errorf("execution failed: %w", err)
+				return i, fmt.Errorf("execution failed: %w", err)
 			}
 			err = tx.Commit()
 			if err != nil {
-				return fmt.Errorf("commit failed: %w", err)
+				return i, fmt.Errorf("commit failed: %w", err)
 			}
 		default:
 			_, err = p.db.Exec(sql, values...)
 			if err != nil {
-				return fmt.Errorf("execution failed: %w", err)
+				return i, fmt.Errorf("execution failed: %w", err)
 			}
 		}
 	}
-	return nil
+	return len(metrics), nil
 }
 
 func init() {
@@ -299,6 +635,7 @@ func newSQL() *SQL {
 		// except max idle connections which is 2. See
 		// https://pkg.go.dev/database/sql#DB.SetMaxIdleConns
 		ConnectionMaxIdle: 2,
+		SpoolMaxSize:      config.Size(100 * 1024 * 1024),
 	}
 }
 
@@ -202,6 +202,7 @@ func TestMysqlIntegration(t *testing.T) {
 	p.DataSourceName = address
 	p.InitSQL = "SET sql_mode='ANSI_QUOTES';"
 
+	require.NoError(t, p.Init())
 	require.NoError(t, p.Connect())
 	require.NoError(t, p.Write(
 		testMetrics,
@@ -288,6 +289,7 @@ func TestPostgresIntegration(t *testing.T) {
 	p.Convert.Unsigned = "bigint"
 	p.Convert.ConversionStyle = "literal"
 
+	require.NoError(t, p.Init())
 	require.NoError(t, p.Connect())
 	defer p.Close()
 	require.NoError(t, p.Write(
@@ -381,6 +383,7 @@ func TestClickHouseIntegration(t *testing.T) {
 	p.Convert.Bool = "UInt8"
 	p.Convert.ConversionStyle = "literal"
 
+	require.NoError(t, p.Init())
 	require.NoError(t, p.Connect())
 	require.NoError(t, p.Write(testMetrics))
 
@@ -456,3 +459,9 @@ func TestClickHouseDsnConvert(t *testing.T) {
 		require.Equal(t, test.expected, convertClickHouseDsn(test.input, log))
 	}
 }
+
+func TestInvalidTimezone(t *testing.T) {
+	p := newSQL()
+	p.Timezone = "Mars/Olympus_Mons"
+	require.ErrorContains(t, p.Init(), "invalid timezone")
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -26,6 +27,7 @@ func TestSqlite(t *testing.T) {
 	p.Driver = "sqlite"
 	p.DataSourceName = address
 
+	require.NoError(t, p.Init())
 	require.NoError(t, p.Connect())
 	defer p.Close()
 	require.NoError(t, p.Write(testMetrics))
@@ -128,3 +130,409 @@ func TestSqlite(t *testing.T) {
 	require.Equal(t, "string2", k)
 	require.False(t, rows4.Next())
 }
+
+func TestSqliteMetadataTable(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = dbfile
+	p.MetadataTable = "telegraf_metadata"
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+	require.NoError(t, p.Write(testMetrics))
+
+	// Writing the same metrics again must not duplicate the metadata
+	require.NoError(t, p.Write(testMetrics))
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(
+		"select table_name, column_name, column_type, origin from telegraf_metadata order by table_name, column_name",
+	)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var actual [][]string
+	for rows.Next() {
+		var table, column, datatype, origin string
+		require.NoError(t, rows.Scan(&table, &column, &datatype, &origin))
+		actual = append(actual, []string{table, column, datatype, origin})
+	}
+	require.NoError(t, rows.Err())
+
+	expected := [][]string{
+		{"metric three", "string two", "TEXT", "field"},
+		{"metric three", "tag four", "TEXT", "tag"},
+		{"metric three", "timestamp", "TIMESTAMP", "timestamp"},
+		{"metric_one", "bool_one", "BOOL", "field"},
+		{"metric_one", "bool_two", "BOOL", "field"},
+		{"metric_one", "float64_one", "DOUBLE", "field"},
+		{"metric_one", "int64_one", "INT", "field"},
+		{"metric_one", "int64_two", "INT", "field"},
+		{"metric_one", "tag_one", "TEXT", "tag"},
+		{"metric_one", "tag_two", "TEXT", "tag"},
+		{"metric_one", "timestamp", "TIMESTAMP", "timestamp"},
+		{"metric_one", "uint64_one", "INT UNSIGNED", "field"},
+		{"metric_two", "string_one", "TEXT", "field"},
+		{"metric_two", "tag_three", "TEXT", "tag"},
+		{"metric_two", "timestamp", "TIMESTAMP", "timestamp"},
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestSqliteUnifyNumericColumns(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = dbfile
+	p.Convert.Real = "REAL"
+	p.UnifyNumericColumns = true
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"metric",
+			map[string]string{},
+			map[string]interface{}{
+				"int64_one":   int64(42),
+				"uint64_one":  uint64(23),
+				"float64_one": 3.5,
+			},
+			ts,
+		),
+	}
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+	require.NoError(t, p.Write(metrics))
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query("select name, type from pragma_table_info('metric')")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, datatype string
+		require.NoError(t, rows.Scan(&name, &datatype))
+		columns[name] = datatype
+	}
+	require.NoError(t, rows.Err())
+	expected := map[string]string{
+		"timestamp":   "TIMESTAMP",
+		"int64_one":   "REAL",
+		"uint64_one":  "REAL",
+		"float64_one": "REAL",
+	}
+	require.Equal(t, expected, columns)
+
+	var intType, uintType, floatType string
+	var intValue, uintValue, floatValue float64
+	require.NoError(t, db.QueryRow(
+		"select typeof(int64_one), int64_one, typeof(uint64_one), uint64_one, typeof(float64_one), float64_one from metric",
+	).Scan(&intType, &intValue, &uintType, &uintValue, &floatType, &floatValue))
+	require.Equal(t, "real", intType)
+	require.InDelta(t, 42.0, intValue, 0)
+	require.Equal(t, "real", uintType)
+	require.InDelta(t, 23.0, uintValue, 0)
+	require.Equal(t, "real", floatType)
+	require.InDelta(t, 3.5, floatValue, 0)
+}
+
+func TestSqliteTimezone(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = dbfile
+	p.Timezone = "UTC"
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"metric",
+			map[string]string{},
+			map[string]interface{}{"value": int64(42)},
+			ts.In(time.FixedZone("UTC+2", 2*60*60)),
+		),
+	}
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+	require.NoError(t, p.Write(metrics))
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var actual string
+	require.NoError(t, db.QueryRow("select timestamp from metric").Scan(&actual))
+	require.Equal(t, "2021-05-17T22:04:45Z", actual)
+}
+
+func TestSqliteWithoutInit(t *testing.T) {
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = filepath.Join(t.TempDir(), "db")
+
+	// Writing must not panic if the timezone was not initialized
+	require.NoError(t, p.Connect())
+	defer p.Close()
+	require.NoError(t, p.Write(testMetrics))
+}
+
+func TestSqliteAddColumns(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+
+	newPlugin := func() *SQL {
+		p := newSQL()
+		p.Log = testutil.Logger{}
+		p.Driver = "sqlite"
+		p.DataSourceName = dbfile
+		p.MetadataTable = "telegraf_metadata"
+		p.TableUpdateTemplate = "ALTER TABLE {TABLE} ADD COLUMN {COLUMN}"
+		require.NoError(t, p.Init())
+		require.NoError(t, p.Connect())
+		return p
+	}
+
+	metric := testutil.MustMetric(
+		"metric",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{"value": int64(42)},
+		ts,
+	)
+	superset := testutil.MustMetric(
+		"metric",
+		map[string]string{"host": "localhost", "region": "eu"},
+		map[string]interface{}{"value": int64(23), "status": "ok"},
+		ts,
+	)
+
+	// Both writers know the table before the schema changes
+	p1 := newPlugin()
+	defer p1.Close()
+	p2 := newPlugin()
+	defer p2.Close()
+	require.NoError(t, p1.Write([]telegraf.Metric{metric}))
+	require.NoError(t, p2.Write([]telegraf.Metric{metric}))
+
+	// Adding the columns by one writer must not fail the other one
+	require.NoError(t, p1.Write([]telegraf.Metric{superset}))
+	require.NoError(t, p2.Write([]telegraf.Metric{superset}))
+	require.NoError(t, p1.Write([]telegraf.Metric{metric, superset}))
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Check the columns were added exactly once
+	rows, err := db.Query("select name, type from pragma_table_info('metric') order by cid")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var columns [][]string
+	for rows.Next() {
+		var name, datatype string
+		require.NoError(t, rows.Scan(&name, &datatype))
+		columns = append(columns, []string{name, datatype})
+	}
+	require.NoError(t, rows.Err())
+	expected := [][]string{
+		{"timestamp", "TIMESTAMP"},
+		{"host", "TEXT"},
+		{"value", "INT"},
+		{"region", "TEXT"},
+		{"status", "TEXT"},
+	}
+	require.Equal(t, expected, columns)
+
+	var count int
+	require.NoError(t, db.QueryRow("select count(*) from metric").Scan(&count))
+	require.Equal(t, 6, count)
+	require.NoError(t, db.QueryRow("select count(*) from metric where status = 'ok' and region = 'eu'").Scan(&count))
+	require.Equal(t, 3, count)
+
+	// The added columns are recorded in the metadata table
+	require.NoError(t, db.QueryRow(
+		"select count(*) from telegraf_metadata where table_name = 'metric' and column_name in ('region', 'status')",
+	).Scan(&count))
+	require.Equal(t, 2, count)
+}
+
+func TestSqliteAddColumnsDisabled(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = dbfile
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	require.NoError(t, p.Write([]telegraf.Metric{
+		testutil.MustMetric("metric", map[string]string{}, map[string]interface{}{"value": int64(42)}, ts),
+	}))
+	require.ErrorContains(t, p.Write([]telegraf.Metric{
+		testutil.MustMetric("metric", map[string]string{}, map[string]interface{}{"value": int64(42), "status": "ok"}, ts),
+	}), "execution failed")
+}
+
+func TestSqliteSpool(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+	spooldir := filepath.Join(t.TempDir(), "spool")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = dbfile
+	p.SpoolDirectory = spooldir
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	// Simulate a database outage, the batch must end up in the spool
+	require.NoError(t, p.db.Close())
+	require.NoError(t, p.Write(testMetrics))
+	files, err := os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Recover the connection, the spooled metrics must be written
+	require.NoError(t, p.Connect())
+	files, err = os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var countMetricOne int
+	require.NoError(t, db.QueryRow("select count(*) from metric_one").Scan(&countMetricOne))
+	require.Equal(t, 1, countMetricOne)
+
+	var countMetricTwo int
+	require.NoError(t, db.QueryRow("select count(*) from metric_two").Scan(&countMetricTwo))
+	require.Equal(t, 1, countMetricTwo)
+
+	var countMetricThree int
+	require.NoError(t, db.QueryRow(`select count(*) from "metric three"`).Scan(&countMetricThree))
+	require.Equal(t, 1, countMetricThree)
+}
+
+func TestSqliteSpoolSizeLimit(t *testing.T) {
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = filepath.Join(t.TempDir(), "db")
+	p.SpoolDirectory = filepath.Join(t.TempDir(), "spool")
+	p.SpoolMaxSize = 10
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	// The batch exceeds the limit so the write error must be returned
+	require.NoError(t, p.db.Close())
+	require.ErrorContains(t, p.Write(testMetrics), "database is closed")
+	files, err := os.ReadDir(p.SpoolDirectory)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestSqliteSpoolPermanentError(t *testing.T) {
+	spooldir := filepath.Join(t.TempDir(), "spool")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = filepath.Join(t.TempDir(), "db")
+	p.SpoolDirectory = spooldir
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	// Metrics lacking the column can never be written due to the constraint
+	_, err := p.db.Exec(`CREATE TABLE "invalid"("timestamp" TIMESTAMP, "value" INT, "required" TEXT NOT NULL)`)
+	require.NoError(t, err)
+	invalid := []telegraf.Metric{
+		testutil.MustMetric("invalid", map[string]string{}, map[string]interface{}{"value": int64(42)}, ts),
+	}
+
+	// The batch must not be spooled as it will never succeed
+	require.ErrorContains(t, p.Write(invalid), "NOT NULL constraint failed")
+	files, err := os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// Spool the batch during a database outage
+	require.NoError(t, p.db.Close())
+	require.NoError(t, p.Write(invalid))
+	files, err = os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Replaying the batch fails, so it must be quarantined
+	require.NoError(t, p.Connect())
+	files, err = os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, spoolFailedExtension, filepath.Ext(files[0].Name()))
+
+	// Following batches must not be blocked by the failing one
+	require.NoError(t, p.Write(testMetrics))
+	files, err = os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	var count int
+	require.NoError(t, p.db.QueryRow("select count(*) from metric_one").Scan(&count))
+	require.Equal(t, 1, count)
+}
+
+func TestSqliteSpoolCorruptFile(t *testing.T) {
+	spooldir := filepath.Join(t.TempDir(), "spool")
+
+	p := newSQL()
+	p.Log = testutil.Logger{}
+	p.Driver = "sqlite"
+	p.DataSourceName = filepath.Join(t.TempDir(), "db")
+	p.SpoolDirectory = spooldir
+
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	// Corrupt spool file e.g. due to a crash while writing
+	corrupt := filepath.Join(spooldir, "00000000000000000001-000001"+spoolFileExtension)
+	require.NoError(t, os.WriteFile(corrupt, []byte("metric_one,tag=a value="), 0640))
+
+	// The corrupt file must be quarantined and not block the new batch
+	require.NoError(t, p.Write(testMetrics))
+	files, err := os.ReadDir(spooldir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, filepath.Base(corrupt)+spoolFailedExtension, files[0].Name())
+
+	var count int
+	require.NoError(t, p.db.QueryRow("select count(*) from metric_one").Scan(&count))
+	require.Equal(t, 1, count)
+}
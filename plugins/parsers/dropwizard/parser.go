@@ -15,6 +15,13 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 )
 
+// unitKeys are the keys of the unit descriptions of meters and timers
+var unitKeys = map[string]bool{
+	"units":          true,
+	"rate_units":     true,
+	"duration_units": true,
+}
+
 // Parser parses json inputs containing dropwizard metrics,
 // either top-level or embedded inside a json field.
 // This parser is using gjson for retrieving paths within the json file.
@@ -26,10 +33,15 @@ type Parser struct {
 	TagPathsMap        map[string]string `toml:"dropwizard_tag_paths_map"`
 	Separator          string            `toml:"separator"`
 	Templates          []string          `toml:"templates"`
+	UnitsAsTags        bool              `toml:"dropwizard_units_as_tags"`
+	Percentiles        []string          `toml:"dropwizard_percentiles"`
+	MetricTypeTag      string            `toml:"dropwizard_metric_type_tag"`
+	MetricTypeValues   map[string]string `toml:"dropwizard_metric_type_values"`
 	DefaultTags        map[string]string `toml:"-"`
 	Log                telegraf.Logger   `toml:"-"`
 
 	templateEngine *templating.Engine
+	percentiles    map[string]bool
 
 	// seriesParser parses line protocol measurement + tags
 	seriesParser *influx.Parser
@@ -202,13 +214,24 @@ func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []te
 				m.SetTime(tm)
 			}
 
-			m.AddTag("metric_type", metricType)
+			if v, found := p.MetricTypeValues[metricType]; found {
+				m.AddTag(p.MetricTypeTag, v)
+			} else {
+				m.AddTag(p.MetricTypeTag, metricType)
+			}
 			for k, v := range tags {
 				m.AddTag(k, v)
 			}
 
 			if fields, ok := dwmFields.(map[string]interface{}); ok {
 				for k, v := range fields {
+					if unit, ok := v.(string); ok && p.UnitsAsTags && unitKeys[k] {
+						m.AddTag(k, unit)
+						continue
+					}
+					if p.skipPercentile(metricType, k) {
+						continue
+					}
 					switch v := v.(type) {
 					case float64, string, bool:
 						m.AddField(fieldPrefix+k, v)
@@ -225,6 +248,31 @@ func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []te
 	return metrics, nil
 }
 
+// skipPercentile returns true if the key is a percentile of a histogram or
+// timer not selected for output
+func (p *Parser) skipPercentile(metricType, key string) bool {
+	if len(p.percentiles) == 0 || (metricType != "histogram" && metricType != "timer") {
+		return false
+	}
+	if !isPercentile(key) {
+		return false
+	}
+	return !p.percentiles[key]
+}
+
+// isPercentile checks if the key has the form "p<digits>" e.g. "p95"
+func isPercentile(key string) bool {
+	if len(key) < 2 || key[0] != 'p' {
+		return false
+	}
+	for _, c := range key[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *Parser) Init() error {
 	parser := &influx.Parser{
 		Type: "series",
@@ -235,6 +283,25 @@ func (p *Parser) Init() error {
 	}
 	p.seriesParser = parser
 
+	if p.MetricTypeTag == "" {
+		p.MetricTypeTag = "metric_type"
+	}
+	for metricType := range p.MetricTypeValues {
+		switch metricType {
+		case "counter", "meter", "gauge", "histogram", "timer":
+		default:
+			return fmt.Errorf("invalid metric type %q in metric type values", metricType)
+		}
+	}
+
+	p.percentiles = make(map[string]bool, len(p.Percentiles))
+	for _, percentile := range p.Percentiles {
+		if !isPercentile(percentile) {
+			return fmt.Errorf("invalid percentile %q", percentile)
+		}
+		p.percentiles[percentile] = true
+	}
+
 	if len(p.Templates) != 0 {
 		defaultTemplate, err := templating.NewDefaultTemplateWithPattern("measurement*")
 		if err != nil {
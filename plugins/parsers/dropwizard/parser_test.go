@@ -235,6 +235,45 @@ func TestParseValidGaugeJSON(t *testing.T) {
 	require.Equal(t, map[string]string{"metric_type": "gauge"}, metrics[0].Tags())
 }
 
+// validNonNumericGaugeJSON is a valid dropwizard json document containing
+// gauges with string and boolean values
+const validNonNumericGaugeJSON = `
+{
+	"version": 		"3.0.0",
+	"counters" : 	{},
+	"meters" : 		{},
+	"gauges" : 		{
+		"connection" : {
+			"value" : "connected"
+		},
+		"healthy" : {
+			"value" : true
+		}
+	},
+	"histograms" : 	{},
+	"timers" : 		{}
+}
+`
+
+func TestParseNonNumericGaugeJSON(t *testing.T) {
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(validNonNumericGaugeJSON))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	connection := search(metrics, "connection", nil, "")
+	require.NotNil(t, connection)
+	require.Equal(t, map[string]interface{}{"value": "connected"}, connection.Fields())
+	require.Equal(t, map[string]string{"metric_type": "gauge"}, connection.Tags())
+
+	healthy := search(metrics, "healthy", nil, "")
+	require.NotNil(t, healthy)
+	require.Equal(t, map[string]interface{}{"value": true}, healthy.Fields())
+	require.Equal(t, map[string]string{"metric_type": "gauge"}, healthy.Tags())
+}
+
 // validHistogramJSON is a valid dropwizard json document containing one histogram
 const validHistogramJSON = `
 {
@@ -285,6 +324,56 @@ func TestParseValidHistogramJSON(t *testing.T) {
 	require.Equal(t, map[string]string{"metric_type": "histogram"}, metrics[0].Tags())
 }
 
+func TestParseHistogramJSONPercentiles(t *testing.T) {
+	parser := &Parser{Percentiles: []string{"p95", "p99"}}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(validHistogramJSON))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{
+		"count":  float64(1),
+		"max":    float64(2),
+		"mean":   float64(3),
+		"min":    float64(4),
+		"p95":    float64(7),
+		"p99":    float64(9),
+		"stddev": float64(11),
+	}, metrics[0].Fields())
+	require.Equal(t, map[string]string{"metric_type": "histogram"}, metrics[0].Tags())
+}
+
+func TestParseInvalidPercentiles(t *testing.T) {
+	parser := &Parser{Percentiles: []string{"p95", "median"}}
+	require.ErrorContains(t, parser.Init(), `invalid percentile "median"`)
+}
+
+func TestParseMetricTypeTag(t *testing.T) {
+	parser := &Parser{
+		MetricTypeTag:    "dw_type",
+		MetricTypeValues: map[string]string{"counter": "ctr"},
+	}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(validAllJSON))
+	require.NoError(t, err)
+	require.Len(t, metrics, 5)
+
+	types := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		require.False(t, m.HasTag("metric_type"))
+		v, found := m.GetTag("dw_type")
+		require.True(t, found)
+		types = append(types, v)
+	}
+	require.ElementsMatch(t, []string{"ctr", "meter", "gauge", "histogram", "timer"}, types)
+}
+
+func TestParseInvalidMetricTypeValues(t *testing.T) {
+	parser := &Parser{MetricTypeValues: map[string]string{"summary": "sum"}}
+	require.ErrorContains(t, parser.Init(), `invalid metric type "summary"`)
+}
+
 // validTimerJSON is a valid dropwizard json document containing one timer
 const validTimerJSON = `
 {
@@ -347,6 +436,23 @@ func TestParseValidTimerJSON(t *testing.T) {
 	require.Equal(t, map[string]string{"metric_type": "timer"}, metrics[0].Tags())
 }
 
+func TestParseTimerJSONUnitsAsTags(t *testing.T) {
+	parser := &Parser{UnitsAsTags: true}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(validTimerJSON))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{
+		"metric_type":    "timer",
+		"duration_units": "seconds",
+		"rate_units":     "calls/second",
+	}, metrics[0].Tags())
+	require.NotContains(t, metrics[0].Fields(), "duration_units")
+	require.NotContains(t, metrics[0].Fields(), "rate_units")
+	require.Len(t, metrics[0].Fields(), 15)
+}
+
 // validAllJSON is a valid dropwizard json document containing one metric of each type
 const validAllJSON = `
 {
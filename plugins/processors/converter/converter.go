@@ -4,16 +4,28 @@ package converter
 import (
 	_ "embed"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"maps"
 	"math"
 	"math/big"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -21,25 +33,86 @@ import (
 var sampleConfig string
 
 type Conversion struct {
-	Measurement       []string `toml:"measurement"`
-	Tag               []string `toml:"tag"`
-	String            []string `toml:"string"`
-	Integer           []string `toml:"integer"`
-	Unsigned          []string `toml:"unsigned"`
-	Boolean           []string `toml:"boolean"`
-	Float             []string `toml:"float"`
-	Timestamp         []string `toml:"timestamp"`
-	TimestampFormat   string   `toml:"timestamp_format"`
-	Base64IEEEFloat32 []string `toml:"base64_ieee_float32"`
+	Measurement         []string `toml:"measurement"`
+	MeasurementTemplate string   `toml:"measurement_template"`
+	Tag                 []string `toml:"tag"`
+	String              []string `toml:"string"`
+	Integer             []string `toml:"integer"`
+	Unsigned            []string `toml:"unsigned"`
+	Boolean             []string `toml:"boolean"`
+	BooleanTrue         []string `toml:"boolean_true"`
+	BooleanFalse        []string `toml:"boolean_false"`
+	Float               []string `toml:"float"`
+	Timestamp           []string `toml:"timestamp"`
+	TimestampFormat     string   `toml:"timestamp_format"`
+	Base64IEEEFloat32   []string `toml:"base64_ieee_float32"`
+	Percent             []string `toml:"percent"`
+	PercentRange        string   `toml:"percent_range"`
+	FloatNonFinite      string   `toml:"float_non_finite"`
+	FloatNonFiniteValue float64  `toml:"float_non_finite_value"`
+	KeepOriginalAsTag   bool     `toml:"keep_original_as_tag"`
+	OriginalTagSuffix   string   `toml:"original_tag_suffix"`
+	Encoding            []string `toml:"encoding"`
+	EncodingSource      string   `toml:"encoding_source"`
+	EncodingInvalid     string   `toml:"encoding_invalid"`
+	Base64              []string `toml:"base64"`
+	Hex                 []string `toml:"hex"`
+	DecodedType         string   `toml:"decoded_type"`
+	HumanSize           []string `toml:"to_human_size"`
+	HumanDuration       []string `toml:"to_human_duration"`
+	HumanKeepOriginal   bool     `toml:"human_keep_original"`
+
+	TimestampCenturyPivot int `toml:"timestamp_century_pivot"`
+
+	TimestampFromFields       []string `toml:"timestamp_from_fields"`
+	TimestampFromFieldsFormat string   `toml:"timestamp_from_fields_format"`
+
+	Scale map[string]float64 `toml:"scale"`
+	Shift map[string]float64 `toml:"shift"`
+
+	charset             encoding.Encoding
+	twoDigitYear        bool
+	measurementTemplate *template.Template
+	round               func(float64) float64
 }
 
 type Converter struct {
-	Tags   *Conversion     `toml:"tags"`
-	Fields *Conversion     `toml:"fields"`
-	Log    telegraf.Logger `toml:"-"`
+	DropTags                   []string          `toml:"drop_tags"`
+	DropFields                 []string          `toml:"drop_fields"`
+	EmitErrorMetrics           bool              `toml:"emit_error_metrics"`
+	MeasurementFromTag         string            `toml:"measurement_from_tag"`
+	MeasurementFromTagTemplate string            `toml:"measurement_from_tag_template"`
+	SampleKey                  string            `toml:"sample_key"`
+	SampleFraction             float64           `toml:"sample_fraction"`
+	SampleTag                  string            `toml:"sample_tag"`
+	FieldPresenceTags          map[string]string `toml:"field_presence_tags"`
+	FieldCountTag              string            `toml:"field_count_tag"`
+	CollisionPolicy            string            `toml:"collision_policy"`
+	FloatRounding              string            `toml:"float_rounding"`
+	Validate                   map[string]string `toml:"validate"`
+	ValidateAction             string            `toml:"validate_action"`
+	ValidateTag                string            `toml:"validate_tag"`
+	Tags                       *Conversion       `toml:"tags"`
+	Fields                     *Conversion       `toml:"fields"`
+	Log                        telegraf.Logger   `toml:"-"`
+
+	dropTags            filter.Filter
+	dropFields          filter.Filter
+	tagConversions      *ConversionFilter
+	fieldConversions    *ConversionFilter
+	measurementTemplate *template.Template
+	fieldPresence       map[string]filter.Filter
+	validations         []validation
 
-	tagConversions   *ConversionFilter
-	fieldConversions *ConversionFilter
+	// number of conversion errors per category for the current metric
+	errorCounts map[string]int64
+}
+
+// validation holds the expression the string values of the fields matching
+// the filter must match
+type validation struct {
+	fields filter.Filter
+	re     *regexp.Regexp
 }
 
 type ConversionFilter struct {
@@ -52,6 +125,12 @@ type ConversionFilter struct {
 	Float             filter.Filter
 	Timestamp         filter.Filter
 	Base64IEEEFloat32 filter.Filter
+	Percent           filter.Filter
+	Encoding          filter.Filter
+	Base64            filter.Filter
+	Hex               filter.Filter
+	HumanSize         filter.Filter
+	HumanDuration     filter.Filter
 }
 
 func (*Converter) SampleConfig() string {
@@ -59,18 +138,140 @@ func (*Converter) SampleConfig() string {
 }
 
 func (p *Converter) Init() error {
+	p.errorCounts = make(map[string]int64)
 	return p.compile()
 }
 
 func (p *Converter) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	var errorMetrics []telegraf.Metric
 	for _, metric := range metrics {
+		name := metric.Name()
+		clear(p.errorCounts)
+
+		p.drop(metric)
 		p.convertTags(metric)
 		p.convertFields(metric)
+		p.measurementFromTag(metric)
+		p.sample(metric)
+		p.fieldTags(metric)
+
+		if p.EmitErrorMetrics && len(p.errorCounts) > 0 {
+			errorMetrics = append(errorMetrics, p.errorMetrics(name, metric.Time())...)
+		}
+	}
+	return append(metrics, errorMetrics...)
+}
+
+// measurementFromTag renames the metric using the configured template if
+// the metric carries the configured tag
+func (p *Converter) measurementFromTag(metric telegraf.Metric) {
+	if p.measurementTemplate == nil {
+		return
+	}
+
+	value, ok := metric.GetTag(p.MeasurementFromTag)
+	if !ok {
+		return
+	}
+
+	var b strings.Builder
+	data := struct{ Name, Value string }{Name: metric.Name(), Value: value}
+	if err := p.measurementTemplate.Execute(&b, data); err != nil {
+		p.conversionError("measurement", value, err)
+		return
+	}
+	if b.Len() == 0 {
+		p.conversionError("measurement", value, errors.New("empty measurement name"))
+		return
+	}
+	metric.SetName(b.String())
+}
+
+// sample tags the metric with a sampling decision based on the hash of the
+// value of the configured tag or field, so that the same value always leads
+// to the same decision
+func (p *Converter) sample(metric telegraf.Metric) {
+	if p.SampleKey == "" {
+		return
+	}
+
+	var value string
+	if v, ok := metric.GetTag(p.SampleKey); ok {
+		value = v
+	} else if v, ok := metric.GetField(p.SampleKey); ok {
+		value = fmt.Sprint(v)
+	} else {
+		return
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	sampled := float64(h.Sum64())/math.MaxUint64 < p.SampleFraction
+	metric.AddTag(p.SampleTag, strconv.FormatBool(sampled))
+}
+
+// fieldTags adds tags reflecting the presence of the configured fields and the
+// number of fields of the metric
+func (p *Converter) fieldTags(metric telegraf.Metric) {
+	for tag, f := range p.fieldPresence {
+		var found bool
+		for _, field := range metric.FieldList() {
+			if f.Match(field.Key) {
+				found = true
+				break
+			}
+		}
+		metric.AddTag(tag, strconv.FormatBool(found))
+	}
+
+	if p.FieldCountTag != "" {
+		metric.AddTag(p.FieldCountTag, strconv.Itoa(len(metric.FieldList())))
+	}
+}
+
+// conversionError logs a failed conversion and records it for the error
+// metrics if enabled
+func (p *Converter) conversionError(category string, value interface{}, err error) {
+	p.Log.Errorf("Converting to %s [%T] failed: %v", category, value, err)
+	if p.EmitErrorMetrics {
+		p.errorCounts[category]++
+	}
+}
+
+// errorMetrics creates one metric per category of the recorded conversion
+// errors for the metric with the given original name
+func (p *Converter) errorMetrics(name string, ts time.Time) []telegraf.Metric {
+	categories := make([]string, 0, len(p.errorCounts))
+	for category := range p.errorCounts {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+
+	metrics := make([]telegraf.Metric, 0, len(categories))
+	for _, category := range categories {
+		tags := map[string]string{
+			"category":    category,
+			"measurement": name,
+		}
+		fields := map[string]interface{}{
+			"count": p.errorCounts[category],
+		}
+		metrics = append(metrics, metric.New("converter_errors", tags, fields, ts))
 	}
 	return metrics
 }
 
 func (p *Converter) compile() error {
+	dt, err := filter.Compile(p.DropTags)
+	if err != nil {
+		return fmt.Errorf("compiling drop_tags failed: %w", err)
+	}
+
+	df, err := filter.Compile(p.DropFields)
+	if err != nil {
+		return fmt.Errorf("compiling drop_fields failed: %w", err)
+	}
+
 	tf, err := compileFilter(p.Tags)
 	if err != nil {
 		return err
@@ -81,10 +282,99 @@ func (p *Converter) compile() error {
 		return err
 	}
 
-	if tf == nil && ff == nil {
+	if tf == nil && ff == nil && dt == nil && df == nil && p.MeasurementFromTag == "" && p.SampleKey == "" &&
+		len(p.FieldPresenceTags) == 0 && p.FieldCountTag == "" && len(p.Validate) == 0 {
 		return errors.New("no filters found")
 	}
 
+	p.fieldPresence = make(map[string]filter.Filter, len(p.FieldPresenceTags))
+	for tag, field := range p.FieldPresenceTags {
+		f, err := filter.Compile([]string{field})
+		if err != nil {
+			return fmt.Errorf("compiling field_presence_tags %q failed: %w", tag, err)
+		}
+		p.fieldPresence[tag] = f
+	}
+
+	p.validations = make([]validation, 0, len(p.Validate))
+	for _, pattern := range slices.Sorted(maps.Keys(p.Validate)) {
+		f, err := filter.Compile([]string{pattern})
+		if err != nil {
+			return fmt.Errorf("compiling validate filter %q failed: %w", pattern, err)
+		}
+		re, err := regexp.Compile(p.Validate[pattern])
+		if err != nil {
+			return fmt.Errorf("compiling validate expression for %q failed: %w", pattern, err)
+		}
+		p.validations = append(p.validations, validation{fields: f, re: re})
+	}
+
+	switch p.ValidateAction {
+	case "":
+		p.ValidateAction = "drop"
+	case "drop", "flag":
+	default:
+		return fmt.Errorf("invalid validate_action %q", p.ValidateAction)
+	}
+	if p.ValidateTag == "" {
+		p.ValidateTag = "invalid_fields"
+	}
+
+	switch p.CollisionPolicy {
+	case "":
+		p.CollisionPolicy = "last"
+	case "first", "last", "error", "suffix":
+	default:
+		return fmt.Errorf("invalid collision_policy %q", p.CollisionPolicy)
+	}
+
+	var round func(float64) float64
+	switch p.FloatRounding {
+	case "", "round":
+		p.FloatRounding = "round"
+		round = math.Round
+	case "floor":
+		round = math.Floor
+	case "ceil":
+		round = math.Ceil
+	case "trunc":
+		round = math.Trunc
+	default:
+		return fmt.Errorf("invalid float_rounding %q", p.FloatRounding)
+	}
+	if p.Tags != nil {
+		p.Tags.round = round
+	}
+	if p.Fields != nil {
+		p.Fields.round = round
+	}
+
+	if p.SampleKey != "" {
+		if p.SampleFraction == 0 {
+			return errors.New("sample_fraction is required when sample_key is set")
+		}
+		if p.SampleFraction < 0 || p.SampleFraction > 1 {
+			return fmt.Errorf("invalid sample_fraction %v, must be between 0 and 1", p.SampleFraction)
+		}
+		if p.SampleTag == "" {
+			p.SampleTag = "sampled"
+		}
+	}
+
+	if p.MeasurementFromTag != "" {
+		if p.MeasurementFromTagTemplate == "" {
+			p.MeasurementFromTagTemplate = "{{.Name}}_{{.Value}}"
+		}
+		tmpl, err := template.New("measurement").Parse(p.MeasurementFromTagTemplate)
+		if err != nil {
+			return fmt.Errorf("compiling measurement_from_tag_template failed: %w", err)
+		}
+		p.measurementTemplate = tmpl
+	}
+
+	p.dropTags = dt
+	p.dropFields = df
+
 	p.tagConversions = tf
 	p.fieldConversions = ff
 	return nil
@@ -95,6 +385,88 @@ func compileFilter(conv *Conversion) (*ConversionFilter, error) {
 		return nil, nil
 	}
 
+	switch conv.FloatNonFinite {
+	case "":
+		conv.FloatNonFinite = "keep"
+	case "keep", "drop", "replace":
+	default:
+		return nil, fmt.Errorf("invalid float_non_finite setting %q", conv.FloatNonFinite)
+	}
+
+	switch conv.PercentRange {
+	case "":
+		conv.PercentRange = "0-100"
+	case "0-100", "0-1":
+	default:
+		return nil, fmt.Errorf("invalid percent_range setting %q", conv.PercentRange)
+	}
+
+	if conv.OriginalTagSuffix == "" {
+		conv.OriginalTagSuffix = "_original"
+	}
+
+	if conv.MeasurementTemplate != "" {
+		tmpl, err := template.New("measurement").Option("missingkey=error").Parse(conv.MeasurementTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("compiling measurement_template failed: %w", err)
+		}
+		conv.measurementTemplate = tmpl
+	}
+
+	for category := range conv.Scale {
+		if !slices.Contains([]string{"integer", "unsigned", "float"}, category) {
+			return nil, fmt.Errorf("invalid scale category %q", category)
+		}
+	}
+	for category := range conv.Shift {
+		if !slices.Contains([]string{"integer", "unsigned", "float"}, category) {
+			return nil, fmt.Errorf("invalid shift category %q", category)
+		}
+	}
+
+	switch conv.DecodedType {
+	case "":
+		conv.DecodedType = "string"
+	case "string", "integer":
+	default:
+		return nil, fmt.Errorf("invalid decoded_type setting %q", conv.DecodedType)
+	}
+
+	switch conv.EncodingInvalid {
+	case "":
+		conv.EncodingInvalid = "replace"
+	case "replace", "skip":
+	default:
+		return nil, fmt.Errorf("invalid encoding_invalid setting %q", conv.EncodingInvalid)
+	}
+
+	if conv.TimestampCenturyPivot < 0 || conv.TimestampCenturyPivot > 100 {
+		return nil, fmt.Errorf("invalid timestamp_century_pivot %d, must be between 0 and 100", conv.TimestampCenturyPivot)
+	}
+	// Only remap layouts containing a two-digit but no four-digit year
+	conv.twoDigitYear = conv.TimestampCenturyPivot > 0 &&
+		strings.Contains(strings.ReplaceAll(conv.TimestampFormat, "2006", ""), "06")
+
+	if len(conv.TimestampFromFields) > 0 {
+		if len(conv.TimestampFromFields) != 2 {
+			return nil, fmt.Errorf("timestamp_from_fields requires exactly two fields but got %d", len(conv.TimestampFromFields))
+		}
+		if conv.TimestampFromFieldsFormat == "" {
+			return nil, errors.New("timestamp_from_fields_format required for timestamp_from_fields")
+		}
+	}
+
+	if len(conv.Encoding) > 0 {
+		if conv.EncodingSource == "" {
+			return nil, errors.New("encoding_source required for encoding conversion")
+		}
+		charset, err := ianaindex.IANA.Encoding(conv.EncodingSource)
+		if err != nil || charset == nil {
+			return nil, fmt.Errorf("unsupported encoding_source %q", conv.EncodingSource)
+		}
+		conv.charset = charset
+	}
+
 	var err error
 	cf := &ConversionFilter{}
 	cf.Measurement, err = filter.Compile(conv.Measurement)
@@ -142,52 +514,120 @@ func compileFilter(conv *Conversion) (*ConversionFilter, error) {
 		return nil, err
 	}
 
+	cf.Percent, err = filter.Compile(conv.Percent)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Encoding, err = filter.Compile(conv.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Base64, err = filter.Compile(conv.Base64)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Hex, err = filter.Compile(conv.Hex)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.HumanSize, err = filter.Compile(conv.HumanSize)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.HumanDuration, err = filter.Compile(conv.HumanDuration)
+	if err != nil {
+		return nil, err
+	}
+
 	return cf, nil
 }
 
+// drop removes the tags and fields matching the drop filters before any
+// conversion takes place
+func (p *Converter) drop(metric telegraf.Metric) {
+	if p.dropTags != nil {
+		for key := range metric.Tags() {
+			if p.dropTags.Match(key) {
+				metric.RemoveTag(key)
+			}
+		}
+	}
+
+	if p.dropFields != nil {
+		for key := range metric.Fields() {
+			if p.dropFields.Match(key) {
+				metric.RemoveField(key)
+			}
+		}
+	}
+}
+
 // convertTags converts tags into measurements or fields.
 func (p *Converter) convertTags(metric telegraf.Metric) {
 	if p.tagConversions == nil {
 		return
 	}
 
+	data := p.Tags.templateData(metric)
 	for key, value := range metric.Tags() {
 		switch {
 		case p.tagConversions.Measurement != nil && p.tagConversions.Measurement.Match(key):
-			metric.SetName(value)
+			if name, err := p.Tags.measurementName(data, value); err != nil {
+				p.conversionError("measurement", value, err)
+			} else {
+				metric.SetName(name)
+			}
 		case p.tagConversions.String != nil && p.tagConversions.String.Match(key):
-			metric.AddField(key, value)
+			p.tagToField(metric, key, value, value)
 		case p.tagConversions.Integer != nil && p.tagConversions.Integer.Match(key):
-			if v, err := toInteger(value); err != nil {
-				p.Log.Errorf("Converting to integer [%T] failed: %v", value, err)
+			if v, err := p.Tags.toScaledInteger(value); err != nil {
+				p.conversionError("integer", value, err)
 			} else {
-				metric.AddField(key, v)
+				p.tagToField(metric, key, value, v)
 			}
 		case p.tagConversions.Unsigned != nil && p.tagConversions.Unsigned.Match(key):
-			if v, err := toUnsigned(value); err != nil {
-				p.Log.Errorf("Converting to unsigned [%T] failed: %v", value, err)
+			if v, err := p.Tags.toScaledUnsigned(value); err != nil {
+				p.conversionError("unsigned", value, err)
 			} else {
-				metric.AddField(key, v)
+				p.tagToField(metric, key, value, v)
 			}
 		case p.tagConversions.Boolean != nil && p.tagConversions.Boolean.Match(key):
-			if v, err := internal.ToBool(value); err != nil {
-				p.Log.Errorf("Converting to boolean [%T] failed: %v", value, err)
+			if v, err := p.Tags.toBool(value); err != nil {
+				p.conversionError("boolean", value, err)
 			} else {
-				metric.AddField(key, v)
+				p.tagToField(metric, key, value, v)
 			}
 		case p.tagConversions.Float != nil && p.tagConversions.Float.Match(key):
-			if v, err := toFloat(value); err != nil {
-				p.Log.Errorf("Converting to float [%T] failed: %v", value, err)
-			} else {
-				metric.AddField(key, v)
+			if v, err := p.Tags.toScaledFloat(value); err != nil {
+				p.conversionError("float", value, err)
+			} else if v, ok := p.Tags.finiteFloat(v); ok {
+				p.tagToField(metric, key, value, v)
 			}
 		case p.tagConversions.Timestamp != nil && p.tagConversions.Timestamp.Match(key):
-			if time, err := internal.ParseTimestamp(p.Tags.TimestampFormat, value, nil); err != nil {
-				p.Log.Errorf("Converting to timestamp [%T] failed: %v", value, err)
+			if time, err := p.Tags.parseTimestamp(value); err != nil {
+				p.conversionError("timestamp", value, err)
 				continue
 			} else {
 				metric.SetTime(time)
 			}
+		case p.tagConversions.Encoding != nil && p.tagConversions.Encoding.Match(key):
+			if v, err := p.Tags.toUTF8(value); err != nil {
+				p.conversionError("encoding", value, err)
+			} else {
+				metric.AddTag(key, v)
+				continue
+			}
+		case p.tagConversions.Base64 != nil && p.tagConversions.Base64.Match(key):
+			p.decodeTag(metric, key, value, "base64", base64.StdEncoding.DecodeString)
+			continue
+		case p.tagConversions.Hex != nil && p.tagConversions.Hex.Match(key):
+			p.decodeTag(metric, key, value, "hex", hex.DecodeString)
+			continue
 		default:
 			continue
 		}
@@ -195,66 +635,135 @@ func (p *Converter) convertTags(metric telegraf.Metric) {
 	}
 }
 
+// tagToField adds the converted value of a tag as field and, if requested,
+// preserves the original tag value under a renamed key.
+func (p *Converter) tagToField(metric telegraf.Metric, key, original string, v interface{}) {
+	p.addField(metric, key, v)
+	if p.Tags.KeepOriginalAsTag {
+		p.addTag(metric, key+p.Tags.OriginalTagSuffix, original)
+	}
+}
+
+// addTag adds a tag created from another key resolving collisions with
+// existing tags according to the collision policy.
+func (p *Converter) addTag(metric telegraf.Metric, key, value string) {
+	if metric.HasTag(key) {
+		var ok bool
+		if key, ok = p.resolveCollision("tag", key, metric.HasTag); !ok {
+			return
+		}
+	}
+	metric.AddTag(key, value)
+}
+
+// addField adds a field created from another key resolving collisions with
+// existing fields according to the collision policy.
+func (p *Converter) addField(metric telegraf.Metric, key string, value interface{}) {
+	if metric.HasField(key) {
+		var ok bool
+		if key, ok = p.resolveCollision("field", key, metric.HasField); !ok {
+			return
+		}
+	}
+	metric.AddField(key, value)
+}
+
+// resolveCollision returns the key to use for a new value colliding with an
+// existing key of the given kind and whether the new value should be added
+// at all.
+func (p *Converter) resolveCollision(kind, key string, exists func(string) bool) (string, bool) {
+	switch p.CollisionPolicy {
+	case "first":
+		p.Log.Debugf("Keeping existing %s %q, dropping converted value", kind, key)
+		return key, false
+	case "error":
+		p.Log.Errorf("Converted %s %q collides with existing %s, dropping converted value", kind, key, kind)
+		if p.EmitErrorMetrics {
+			p.errorCounts["collision"]++
+		}
+		return key, false
+	case "suffix":
+		for i := 1; ; i++ {
+			candidate := key + "_" + strconv.Itoa(i)
+			if !exists(candidate) {
+				p.Log.Debugf("Renaming converted %s %q to %q due to collision", kind, key, candidate)
+				return candidate, true
+			}
+		}
+	}
+	p.Log.Debugf("Overwriting existing %s %q with converted value", kind, key)
+	return key, true
+}
+
 // convertFields converts fields into measurements, tags, or other field types.
 func (p *Converter) convertFields(metric telegraf.Metric) {
+	p.validateFields(metric)
+
 	if p.fieldConversions == nil {
 		return
 	}
 
+	p.timestampFromFields(metric)
+
+	data := p.Fields.templateData(metric)
 	for key, value := range metric.Fields() {
 		switch {
 		case p.fieldConversions.Measurement != nil && p.fieldConversions.Measurement.Match(key):
 			if v, err := internal.ToString(value); err != nil {
-				p.Log.Errorf("Converting to measurement [%T] failed: %v", value, err)
+				p.conversionError("measurement", value, err)
+			} else if name, err := p.Fields.measurementName(data, v); err != nil {
+				p.conversionError("measurement", value, err)
 			} else {
-				metric.SetName(v)
+				metric.SetName(name)
 			}
 			metric.RemoveField(key)
 		case p.fieldConversions.Tag != nil && p.fieldConversions.Tag.Match(key):
 			if v, err := internal.ToString(value); err != nil {
-				p.Log.Errorf("Converting to tag [%T] failed: %v", value, err)
+				p.conversionError("tag", value, err)
 			} else {
-				metric.AddTag(key, v)
+				p.addTag(metric, key, v)
 			}
 			metric.RemoveField(key)
 		case p.fieldConversions.Float != nil && p.fieldConversions.Float.Match(key):
-			if v, err := toFloat(value); err != nil {
-				p.Log.Errorf("Converting to float [%T] failed: %v", value, err)
+			if v, err := p.Fields.toScaledFloat(value); err != nil {
+				p.conversionError("float", value, err)
+				metric.RemoveField(key)
+			} else if v, ok := p.Fields.finiteFloat(v); !ok {
 				metric.RemoveField(key)
 			} else {
 				metric.AddField(key, v)
 			}
 		case p.fieldConversions.Integer != nil && p.fieldConversions.Integer.Match(key):
-			if v, err := toInteger(value); err != nil {
-				p.Log.Errorf("Converting to integer [%T] failed: %v", value, err)
+			if v, err := p.Fields.toScaledInteger(value); err != nil {
+				p.conversionError("integer", value, err)
 				metric.RemoveField(key)
 			} else {
 				metric.AddField(key, v)
 			}
 		case p.fieldConversions.Unsigned != nil && p.fieldConversions.Unsigned.Match(key):
-			if v, err := toUnsigned(value); err != nil {
-				p.Log.Errorf("Converting to unsigned [%T] failed: %v", value, err)
+			if v, err := p.Fields.toScaledUnsigned(value); err != nil {
+				p.conversionError("unsigned", value, err)
 				metric.RemoveField(key)
 			} else {
 				metric.AddField(key, v)
 			}
 		case p.fieldConversions.Boolean != nil && p.fieldConversions.Boolean.Match(key):
-			if v, err := internal.ToBool(value); err != nil {
-				p.Log.Errorf("Converting to bool [%T] failed: %v", value, err)
+			if v, err := p.Fields.toBool(value); err != nil {
+				p.conversionError("boolean", value, err)
 				metric.RemoveField(key)
 			} else {
 				metric.AddField(key, v)
 			}
 		case p.fieldConversions.String != nil && p.fieldConversions.String.Match(key):
 			if v, err := internal.ToString(value); err != nil {
-				p.Log.Errorf("Converting to string [%T] failed: %v", value, err)
+				p.conversionError("string", value, err)
 				metric.RemoveField(key)
 			} else {
 				metric.AddField(key, v)
 			}
 		case p.fieldConversions.Timestamp != nil && p.fieldConversions.Timestamp.Match(key):
-			if time, err := internal.ParseTimestamp(p.Fields.TimestampFormat, value, nil); err != nil {
-				p.Log.Errorf("Converting to timestamp [%T] failed: %v", value, err)
+			if time, err := p.Fields.parseTimestamp(value); err != nil {
+				p.conversionError("timestamp", value, err)
 			} else {
 				metric.SetTime(time)
 				metric.RemoveField(key)
@@ -262,16 +771,172 @@ func (p *Converter) convertFields(metric telegraf.Metric) {
 
 		case p.fieldConversions.Base64IEEEFloat32 != nil && p.fieldConversions.Base64IEEEFloat32.Match(key):
 			if v, err := base64ToFloat32(value.(string)); err != nil {
-				p.Log.Errorf("Converting to base64_ieee_float32 [%T] failed: %v", value, err)
+				p.conversionError("base64_ieee_float32", value, err)
 				metric.RemoveField(key)
 			} else {
 				metric.AddField(key, v)
 			}
+		case p.fieldConversions.Percent != nil && p.fieldConversions.Percent.Match(key):
+			if v, err := p.Fields.toPercent(value); err != nil {
+				p.conversionError("percent", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.Encoding != nil && p.fieldConversions.Encoding.Match(key):
+			if v, ok := value.(string); !ok {
+				p.conversionError("encoding", value, errors.New("not a string"))
+				metric.RemoveField(key)
+			} else if v, err := p.Fields.toUTF8(v); err != nil {
+				p.conversionError("encoding", value, err)
+				metric.RemoveField(key)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.Base64 != nil && p.fieldConversions.Base64.Match(key):
+			if v, err := p.Fields.decode(value, base64.StdEncoding.DecodeString); err != nil {
+				p.conversionError("base64", value, err)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.Hex != nil && p.fieldConversions.Hex.Match(key):
+			if v, err := p.Fields.decode(value, hex.DecodeString); err != nil {
+				p.conversionError("hex", value, err)
+			} else {
+				metric.AddField(key, v)
+			}
+		case p.fieldConversions.HumanSize != nil && p.fieldConversions.HumanSize.Match(key):
+			if v, err := toInteger(value, math.Round); err != nil {
+				p.conversionError("to_human_size", value, err)
+				metric.RemoveField(key)
+			} else {
+				p.addHumanField(metric, key, humanSize(v))
+			}
+		case p.fieldConversions.HumanDuration != nil && p.fieldConversions.HumanDuration.Match(key):
+			if v, err := toInteger(value, math.Round); err != nil {
+				p.conversionError("to_human_duration", value, err)
+				metric.RemoveField(key)
+			} else {
+				p.addHumanField(metric, key, time.Duration(v).String())
+			}
 		}
 	}
 }
 
-func toInteger(v interface{}) (int64, error) {
+// decodeTag decodes the tag value keeping decoded strings as tag and
+// converting decoded integers to a field. The tag is left unchanged if
+// decoding fails.
+func (p *Converter) decodeTag(metric telegraf.Metric, key, value, category string, decoder func(string) ([]byte, error)) {
+	v, err := p.Tags.decode(value, decoder)
+	if err != nil {
+		p.conversionError(category, value, err)
+		return
+	}
+
+	if s, ok := v.(string); ok {
+		metric.AddTag(key, s)
+		return
+	}
+	metric.RemoveTag(key)
+	p.tagToField(metric, key, value, v)
+}
+
+// addHumanField adds the human-readable representation of the field either
+// replacing the original value or as additional field with "_human" suffix
+func (p *Converter) addHumanField(metric telegraf.Metric, key, value string) {
+	if p.Fields.HumanKeepOriginal {
+		metric.AddField(key+"_human", value)
+	} else {
+		metric.AddField(key, value)
+	}
+}
+
+// humanSize formats the number of bytes using binary (IEC) prefixes rounded
+// to one decimal place, e.g. "1.5 GiB"
+func humanSize(v int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+	size := math.Abs(float64(v))
+	var idx int
+	for size >= 1024 && idx < len(units)-1 {
+		size /= 1024
+		idx++
+	}
+	size = math.Round(size*10) / 10
+	if v < 0 {
+		size = -size
+	}
+	return strconv.FormatFloat(size, 'f', -1, 64) + " " + units[idx]
+}
+
+// validateFields checks the string fields against the configured
+// expressions. Invalid fields are either dropped or their names are added
+// to the validation tag as comma-separated list.
+func (p *Converter) validateFields(metric telegraf.Metric) {
+	if len(p.validations) == 0 {
+		return
+	}
+
+	var invalid []string
+	for _, field := range metric.FieldList() {
+		value, ok := field.Value.(string)
+		if !ok {
+			continue
+		}
+		for _, v := range p.validations {
+			if v.fields.Match(field.Key) && !v.re.MatchString(value) {
+				invalid = append(invalid, field.Key)
+				break
+			}
+		}
+	}
+	if len(invalid) == 0 {
+		return
+	}
+
+	if p.ValidateAction == "flag" {
+		slices.Sort(invalid)
+		metric.AddTag(p.ValidateTag, strings.Join(invalid, ","))
+		return
+	}
+	for _, key := range invalid {
+		metric.RemoveField(key)
+	}
+}
+
+// timestampFromFields sets the metric time from the combination of the
+// configured date and time fields. The values are joined by a space and
+// parsed using the configured format. Metrics missing one of the fields are
+// left untouched.
+func (p *Converter) timestampFromFields(metric telegraf.Metric) {
+	if len(p.Fields.TimestampFromFields) != 2 {
+		return
+	}
+
+	dateKey, timeKey := p.Fields.TimestampFromFields[0], p.Fields.TimestampFromFields[1]
+	dateValue, found := metric.GetField(dateKey)
+	if !found {
+		return
+	}
+	timeValue, found := metric.GetField(timeKey)
+	if !found {
+		return
+	}
+
+	value := fmt.Sprintf("%v %v", dateValue, timeValue)
+	t, err := time.Parse(p.Fields.TimestampFromFieldsFormat, value)
+	if err != nil {
+		p.conversionError("timestamp", value, err)
+		return
+	}
+	metric.SetTime(t)
+	metric.RemoveField(dateKey)
+	metric.RemoveField(timeKey)
+}
+
+// toInteger converts the value to an integer using the given function for
+// rounding floating-point values
+func toInteger(v interface{}, round func(float64) float64) (int64, error) {
 	switch value := v.(type) {
 	case float32:
 		if value < float32(math.MinInt64) {
@@ -280,7 +945,7 @@ func toInteger(v interface{}) (int64, error) {
 		if value > float32(math.MaxInt64) {
 			return math.MaxInt64, nil
 		}
-		return int64(math.Round(float64(value))), nil
+		return int64(round(float64(value))), nil
 	case float64:
 		if value < float64(math.MinInt64) {
 			return math.MinInt64, nil
@@ -288,7 +953,7 @@ func toInteger(v interface{}) (int64, error) {
 		if value > float64(math.MaxInt64) {
 			return math.MaxInt64, nil
 		}
-		return int64(math.Round(value)), nil
+		return int64(round(value)), nil
 	default:
 		if v, err := internal.ToInt64(value); err == nil {
 			return v, nil
@@ -305,11 +970,11 @@ func toInteger(v interface{}) (int64, error) {
 		if v > float64(math.MaxInt64) {
 			return math.MaxInt64, nil
 		}
-		return int64(math.Round(v)), nil
+		return int64(round(v)), nil
 	}
 }
 
-func toUnsigned(v interface{}) (uint64, error) {
+func toUnsigned(v interface{}, round func(float64) float64) (uint64, error) {
 	switch value := v.(type) {
 	case float32:
 		if value < 0 {
@@ -318,7 +983,7 @@ func toUnsigned(v interface{}) (uint64, error) {
 		if value > float32(math.MaxUint64) {
 			return math.MaxUint64, nil
 		}
-		return uint64(math.Round(float64(value))), nil
+		return uint64(round(float64(value))), nil
 	case float64:
 		if value < 0 {
 			return 0, nil
@@ -326,7 +991,7 @@ func toUnsigned(v interface{}) (uint64, error) {
 		if value > float64(math.MaxUint64) {
 			return math.MaxUint64, nil
 		}
-		return uint64(math.Round(value)), nil
+		return uint64(round(value)), nil
 	default:
 		if v, err := internal.ToUint64(value); err == nil {
 			return v, nil
@@ -343,7 +1008,7 @@ func toUnsigned(v interface{}) (uint64, error) {
 		if v > float64(math.MaxUint64) {
 			return math.MaxUint64, nil
 		}
-		return uint64(math.Round(v)), nil
+		return uint64(round(v)), nil
 	}
 }
 
@@ -363,6 +1028,218 @@ func toFloat(v interface{}) (float64, error) {
 	return internal.ToFloat64(v)
 }
 
+// toBool converts the value to a boolean using the configured truthy and
+// falsy values before falling back to the default conversion.
+func (c *Conversion) toBool(v interface{}) (bool, error) {
+	if s, ok := v.(string); ok {
+		for _, t := range c.BooleanTrue {
+			if strings.EqualFold(s, t) {
+				return true, nil
+			}
+		}
+		for _, f := range c.BooleanFalse {
+			if strings.EqualFold(s, f) {
+				return false, nil
+			}
+		}
+	}
+	return internal.ToBool(v)
+}
+
+// toPercent converts the value to a percentage in the configured range.
+// Strings with a "%" suffix are interpreted as percentage, all other values
+// as ratio in the range of 0 to 1. The result is clamped to the range.
+func (c *Conversion) toPercent(v interface{}) (float64, error) {
+	var ratio float64
+	if s, ok := v.(string); ok && strings.HasSuffix(strings.TrimSpace(s), "%") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+		if err != nil {
+			return 0, err
+		}
+		ratio = f / 100
+	} else {
+		f, err := toFloat(v)
+		if err != nil {
+			return 0, err
+		}
+		ratio = f
+	}
+	if math.IsNaN(ratio) {
+		return 0, errors.New("not a number")
+	}
+
+	ratio = math.Max(0, math.Min(1, ratio))
+	if c.PercentRange == "0-1" {
+		return ratio, nil
+	}
+	return ratio * 100, nil
+}
+
+// toUTF8 decodes the given string from the source encoding into UTF-8.
+// Sequences invalid in the source encoding are replaced by the Unicode
+// replacement character or result in an error depending on the policy.
+func (c *Conversion) toUTF8(v string) (string, error) {
+	decoded, err := c.charset.NewDecoder().String(v)
+	if err != nil {
+		return "", err
+	}
+	if c.EncodingInvalid == "skip" && strings.ContainsRune(decoded, utf8.RuneError) {
+		return "", fmt.Errorf("invalid %s sequence in %q", c.EncodingSource, v)
+	}
+	return decoded, nil
+}
+
+// parseTimestamp parses the value using the configured format. Two-digit
+// years are mapped to the century determined by the configured pivot, i.e.
+// years below the pivot are placed in the 2000s and all others in the 1900s.
+func (c *Conversion) parseTimestamp(v interface{}) (time.Time, error) {
+	t, err := internal.ParseTimestamp(c.TimestampFormat, v, nil)
+	if err != nil || !c.twoDigitYear {
+		return t, err
+	}
+
+	year := t.Year() % 100
+	if year < c.TimestampCenturyPivot {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	return t.AddDate(year-t.Year(), 0, 0), nil
+}
+
+// scale multiplies the value by the scale and adds the shift configured for
+// the category. It returns false if neither is configured.
+func (c *Conversion) scale(category string, v float64) (float64, bool) {
+	scale, hasScale := c.Scale[category]
+	shift, hasShift := c.Shift[category]
+	if !hasScale && !hasShift {
+		return v, false
+	}
+	if !hasScale {
+		scale = 1
+	}
+	return v*scale + shift, true
+}
+
+func (c *Conversion) toScaledFloat(v interface{}) (float64, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	f, _ = c.scale("float", f)
+	return f, nil
+}
+
+func (c *Conversion) toScaledInteger(v interface{}) (int64, error) {
+	i, err := toInteger(v, c.round)
+	if err != nil {
+		return 0, err
+	}
+	f, scaled := c.scale("integer", float64(i))
+	if !scaled {
+		return i, nil
+	}
+	f = c.round(f)
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("scaled value %v overflows integer", f)
+	}
+	return int64(f), nil
+}
+
+func (c *Conversion) toScaledUnsigned(v interface{}) (uint64, error) {
+	u, err := toUnsigned(v, c.round)
+	if err != nil {
+		return 0, err
+	}
+	f, scaled := c.scale("unsigned", float64(u))
+	if !scaled {
+		return u, nil
+	}
+	f = c.round(f)
+	if f < 0 || f >= math.MaxUint64 {
+		return 0, fmt.Errorf("scaled value %v overflows unsigned", f)
+	}
+	return uint64(f), nil
+}
+
+// templateData returns the tags and fields of the metric for rendering the
+// measurement template, fields take precedence over tags with the same key.
+// It returns nil if no template is configured.
+func (c *Conversion) templateData(metric telegraf.Metric) map[string]interface{} {
+	if c.measurementTemplate == nil {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(metric.TagList())+len(metric.FieldList()))
+	for _, tag := range metric.TagList() {
+		data[tag.Key] = tag.Value
+	}
+	for _, field := range metric.FieldList() {
+		data[field.Key] = field.Value
+	}
+	return data
+}
+
+// measurementName returns the given value or, if configured, the rendered
+// measurement template
+func (c *Conversion) measurementName(data map[string]interface{}, value string) (string, error) {
+	if c.measurementTemplate == nil {
+		return value, nil
+	}
+
+	var b strings.Builder
+	if err := c.measurementTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// decode decodes the string value using the given decoder and returns the
+// raw bytes as string or, for the "integer" decoded type, as big-endian
+// integer of at most eight bytes.
+func (c *Conversion) decode(v interface{}, decoder func(string) ([]byte, error)) (interface{}, error) {
+	encoded, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+
+	raw, err := decoder(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.DecodedType != "integer" {
+		return string(raw), nil
+	}
+	if len(raw) == 0 || len(raw) > 8 {
+		return nil, fmt.Errorf("cannot convert %d bytes to integer", len(raw))
+	}
+	var value uint64
+	for _, b := range raw {
+		value = value<<8 | uint64(b)
+	}
+	if value > math.MaxInt64 {
+		return nil, fmt.Errorf("value %d overflows integer", value)
+	}
+	return int64(value), nil
+}
+
+// finiteFloat applies the configured handling of NaN and infinite values.
+// It returns false if the value should be dropped.
+func (c *Conversion) finiteFloat(v float64) (float64, bool) {
+	if !math.IsNaN(v) && !math.IsInf(v, 0) {
+		return v, true
+	}
+
+	switch c.FloatNonFinite {
+	case "drop":
+		return 0, false
+	case "replace":
+		return c.FloatNonFiniteValue, true
+	}
+	return v, true
+}
+
 func base64ToFloat32(encoded string) (float32, error) {
 	// Decode the Base64 string to bytes
 	decodedBytes, err := base64.StdEncoding.DecodeString(encoded)
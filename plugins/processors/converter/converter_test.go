@@ -2,6 +2,7 @@ package converter
 
 import (
 	"math"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -62,6 +63,67 @@ func TestConverter(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "from tag keeping original as tag",
+			converter: &Converter{
+				Tags: &Conversion{
+					Integer:           []string{"code"},
+					KeepOriginalAsTag: true,
+				},
+			},
+			input: testutil.MustMetric(
+				"http",
+				map[string]string{
+					"code": "200",
+					"host": "localhost",
+				},
+				map[string]interface{}{},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{
+						"code_original": "200",
+						"host":          "localhost",
+					},
+					map[string]interface{}{
+						"code": int64(200),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "from tag keeping original with custom suffix",
+			converter: &Converter{
+				Tags: &Conversion{
+					String:            []string{"status"},
+					KeepOriginalAsTag: true,
+					OriginalTagSuffix: "_tag",
+				},
+			},
+			input: testutil.MustMetric(
+				"http",
+				map[string]string{
+					"status": "ok",
+				},
+				map[string]interface{}{},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{
+						"status_tag": "ok",
+					},
+					map[string]interface{}{
+						"status": "ok",
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
 		{
 			name: "from tag unconvertible",
 			converter: &Converter{
@@ -708,6 +770,111 @@ func TestMultipleTimestamps(t *testing.T) {
 	require.Len(t, result[0].FieldList(), 1)
 }
 
+func TestTimestampCenturyPivot(t *testing.T) {
+	tests := []struct {
+		name     string
+		pivot    int
+		format   string
+		value    string
+		expected time.Time
+	}{
+		{
+			name:     "default mapping",
+			format:   "02/Jan/06",
+			value:    "06/Apr/70",
+			expected: time.Date(1970, time.April, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "below pivot",
+			pivot:    80,
+			format:   "02/Jan/06",
+			value:    "06/Apr/70",
+			expected: time.Date(2070, time.April, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "above pivot",
+			pivot:    20,
+			format:   "02/Jan/06",
+			value:    "06/Apr/24",
+			expected: time.Date(1924, time.April, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "at pivot",
+			pivot:    24,
+			format:   "02/Jan/06 15:04:05",
+			value:    "06/Apr/24 13:14:15",
+			expected: time.Date(1924, time.April, 6, 13, 14, 15, 0, time.UTC),
+		},
+		{
+			name:     "pivot 100",
+			pivot:    100,
+			format:   "02/Jan/06",
+			value:    "06/Apr/99",
+			expected: time.Date(2099, time.April, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "four-digit year unaffected",
+			pivot:    50,
+			format:   "02/Jan/2006",
+			value:    "06/Apr/1924",
+			expected: time.Date(1924, time.April, 6, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &Converter{
+				Tags: &Conversion{
+					Timestamp:             []string{"time"},
+					TimestampFormat:       tt.format,
+					TimestampCenturyPivot: tt.pivot,
+				},
+				Fields: &Conversion{
+					Timestamp:             []string{"time"},
+					TimestampFormat:       tt.format,
+					TimestampCenturyPivot: tt.pivot,
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			input := []telegraf.Metric{
+				testutil.MustMetric(
+					"tags",
+					map[string]string{"time": tt.value},
+					map[string]interface{}{"a": 42.0},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric(
+					"fields",
+					map[string]string{},
+					map[string]interface{}{"a": 42.0, "time": tt.value},
+					time.Unix(0, 0),
+				),
+			}
+			expected := []telegraf.Metric{
+				testutil.MustMetric("tags", map[string]string{}, map[string]interface{}{"a": 42.0}, tt.expected),
+				testutil.MustMetric("fields", map[string]string{}, map[string]interface{}{"a": 42.0}, tt.expected),
+			}
+
+			actual := converter.Apply(input...)
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestInvalidTimestampCenturyPivot(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			Timestamp:             []string{"time"},
+			TimestampFormat:       "02/Jan/06",
+			TimestampCenturyPivot: 101,
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid timestamp_century_pivot")
+}
+
 func TestMeasurement(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -798,72 +965,1504 @@ func TestMeasurement(t *testing.T) {
 				),
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.converter.Log = testutil.Logger{}
-			require.NoError(t, tt.converter.Init())
-
-			actual := tt.converter.Apply(tt.input)
-			testutil.RequireMetricsEqual(t, tt.expected, actual)
-		})
-	}
-}
-
-func TestEmptyConfigInitError(t *testing.T) {
-	converter := &Converter{
-		Log: testutil.Logger{},
-	}
-	require.Error(t, converter.Init())
-}
-
-func TestTracking(t *testing.T) {
-	inputRaw := []telegraf.Metric{
-		metric.New("foo", map[string]string{}, map[string]interface{}{"value": 42, "topic": "telegraf"}, time.Unix(0, 0)),
-		metric.New("bar", map[string]string{}, map[string]interface{}{"value": 42, "topic": "telegraf"}, time.Unix(0, 0)),
-		metric.New("baz", map[string]string{}, map[string]interface{}{"value": 42, "topic": "telegraf"}, time.Unix(0, 0)),
-	}
-
-	var mu sync.Mutex
-	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
-	notify := func(di telegraf.DeliveryInfo) {
-		mu.Lock()
-		defer mu.Unlock()
-		delivered = append(delivered, di)
-	}
-
-	input := make([]telegraf.Metric, 0, len(inputRaw))
-	for _, m := range inputRaw {
-		tm, _ := metric.WithTracking(m, notify)
-		input = append(input, tm)
-	}
-
-	expected := []telegraf.Metric{
-		metric.New("telegraf", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
-		metric.New("telegraf", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
-		metric.New("telegraf", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
-	}
-
-	plugin := &Converter{
-		Fields: &Conversion{
-			Measurement: []string{"topic"},
+		{
+			name: "non-finite float kept by default",
+			converter: &Converter{
+				Fields: &Conversion{
+					Float: []string{"a", "b"},
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": "+Inf",
+					"b": 42,
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": math.Inf(1),
+						"b": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
 		},
-	}
-	require.NoError(t, plugin.Init())
-
-	// Process expected metrics and compare with resulting metrics
-	actual := plugin.Apply(input...)
-	testutil.RequireMetricsEqual(t, expected, actual)
-
-	// Simulate output acknowledging delivery
-	for _, m := range actual {
-		m.Accept()
-	}
-
-	// Check delivery
-	require.Eventuallyf(t, func() bool {
-		mu.Lock()
-		defer mu.Unlock()
-		return len(input) == len(delivered)
-	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
+		{
+			name: "non-finite float replaced",
+			converter: &Converter{
+				Fields: &Conversion{
+					Float:               []string{"a", "b", "c"},
+					FloatNonFinite:      "replace",
+					FloatNonFiniteValue: -1,
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": math.NaN(),
+					"b": "+Inf",
+					"c": 42,
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": -1.0,
+						"b": -1.0,
+						"c": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "non-finite float dropped",
+			converter: &Converter{
+				Tags: &Conversion{
+					Float:          []string{"t"},
+					FloatNonFinite: "drop",
+				},
+				Fields: &Conversion{
+					Float:          []string{"a", "b", "c"},
+					FloatNonFinite: "drop",
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"t": "NaN",
+				},
+				map[string]interface{}{
+					"a": math.NaN(),
+					"b": "-Inf",
+					"c": 42,
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"c": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "encoding latin1 to utf8",
+			converter: &Converter{
+				Tags: &Conversion{
+					Encoding:       []string{"city"},
+					EncodingSource: "latin1",
+				},
+				Fields: &Conversion{
+					Encoding:       []string{"a", "b"},
+					EncodingSource: "latin1",
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"city": "M\xfcnchen",
+				},
+				map[string]interface{}{
+					"a": "caf\xe9",
+					"b": int64(42),
+					"c": "caf\xe9",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"city": "München",
+					},
+					map[string]interface{}{
+						"a": "café",
+						"c": "caf\xe9",
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "encoding skip invalid",
+			converter: &Converter{
+				Tags: &Conversion{
+					Encoding:        []string{"*"},
+					EncodingSource:  "windows-1252",
+					EncodingInvalid: "skip",
+				},
+				Fields: &Conversion{
+					Encoding:       []string{"*"},
+					EncodingSource: "windows-1252",
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"valid":   "\x80 5",
+					"invalid": "a\x81b",
+				},
+				map[string]interface{}{
+					"a": "a\x81b",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"valid": "€ 5",
+					},
+					map[string]interface{}{
+						"a": "a\ufffdb",
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "percent",
+			converter: &Converter{
+				Fields: &Conversion{
+					Percent: []string{"*"},
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": "45%",
+					"b": 0.45,
+					"c": "150%",
+					"d": "-3 %",
+					"e": "0.5",
+					"f": "NaN",
+					"g": "foo%",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": 45.0,
+						"b": 45.0,
+						"c": 100.0,
+						"d": 0.0,
+						"e": 50.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "percent as ratio",
+			converter: &Converter{
+				Fields: &Conversion{
+					Percent:      []string{"*"},
+					PercentRange: "0-1",
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": "45%",
+					"b": 0.45,
+					"c": "150%",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": 0.45,
+						"b": 0.45,
+						"c": 1.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "boolean from on/off",
+			converter: &Converter{
+				Tags: &Conversion{
+					Boolean:      []string{"t"},
+					BooleanTrue:  []string{"on"},
+					BooleanFalse: []string{"off"},
+				},
+				Fields: &Conversion{
+					Boolean:      []string{"a", "b", "c"},
+					BooleanTrue:  []string{"on"},
+					BooleanFalse: []string{"off"},
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"t": "ON",
+				},
+				map[string]interface{}{
+					"a": "on",
+					"b": "Off",
+					"c": "true",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"t": true,
+						"a": true,
+						"b": false,
+						"c": true,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "boolean from localized values",
+			converter: &Converter{
+				Fields: &Conversion{
+					Boolean:      []string{"a", "b", "c"},
+					BooleanTrue:  []string{"ja", "oui"},
+					BooleanFalse: []string{"nein", "non"},
+				},
+			},
+			input: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": "Ja",
+					"b": "non",
+					"c": "vielleicht",
+				},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": true,
+						"b": false,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.converter.Log = testutil.Logger{}
+			require.NoError(t, tt.converter.Init())
+
+			actual := tt.converter.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInvalidPercentRange(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			Percent:      []string{"a"},
+			PercentRange: "0-10",
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid percent_range setting")
+}
+
+func TestInvalidEncoding(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			Encoding:       []string{"a"},
+			EncodingSource: "klingon",
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "unsupported encoding_source")
+
+	converter = &Converter{
+		Fields: &Conversion{
+			Encoding:        []string{"a"},
+			EncodingSource:  "latin1",
+			EncodingInvalid: "ignore",
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid encoding_invalid setting")
+}
+
+func TestDropTagsAndFields(t *testing.T) {
+	converter := &Converter{
+		DropTags:   []string{"host", "debug_*"},
+		DropFields: []string{"noise*"},
+		Fields: &Conversion{
+			Integer: []string{"a"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host":        "localhost",
+			"debug_id":    "42",
+			"region":      "eu",
+			"debug_trace": "abc",
+		},
+		map[string]interface{}{
+			"a":        "1",
+			"noise":    1.0,
+			"noise_id": "x",
+			"value":    2.0,
+		},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"region": "eu",
+			},
+			map[string]interface{}{
+				"a":     int64(1),
+				"value": 2.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := converter.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestDropOnlyConfig(t *testing.T) {
+	converter := &Converter{
+		DropFields: []string{"noise"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"noise": 1.0,
+			"value": 2.0,
+		},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 2.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := converter.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestEmitErrorMetrics(t *testing.T) {
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "a",
+		},
+		map[string]interface{}{
+			"a": "not a number",
+			"b": "neither",
+			"c": "maybe",
+		},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"host": "a",
+			},
+			map[string]interface{}{},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"converter_errors",
+			map[string]string{
+				"category":    "boolean",
+				"measurement": "cpu",
+			},
+			map[string]interface{}{
+				"count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"converter_errors",
+			map[string]string{
+				"category":    "integer",
+				"measurement": "cpu",
+			},
+			map[string]interface{}{
+				"count": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &Converter{
+		EmitErrorMetrics: true,
+		Fields: &Conversion{
+			Integer: []string{"a", "b"},
+			Boolean: []string{"c"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	actual := plugin.Apply(input.Copy())
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Without the option only the converted metric is returned
+	plugin = &Converter{
+		Fields: &Conversion{
+			Integer: []string{"a", "b"},
+			Boolean: []string{"c"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	actual = plugin.Apply(input.Copy())
+	testutil.RequireMetricsEqual(t, expected[:1], actual)
+}
+
+func TestMeasurementFromTag(t *testing.T) {
+	converter := &Converter{
+		MeasurementFromTag: "severity",
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"logs",
+			map[string]string{"severity": "error"},
+			map[string]interface{}{"message": "failed"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"logs",
+			map[string]string{"severity": "info"},
+			map[string]interface{}{"message": "started"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"logs",
+			map[string]string{},
+			map[string]interface{}{"message": "unknown"},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"logs_error",
+			map[string]string{"severity": "error"},
+			map[string]interface{}{"message": "failed"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"logs_info",
+			map[string]string{"severity": "info"},
+			map[string]interface{}{"message": "started"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"logs",
+			map[string]string{},
+			map[string]interface{}{"message": "unknown"},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := converter.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestMeasurementFromTagTemplate(t *testing.T) {
+	converter := &Converter{
+		MeasurementFromTag:         "severity",
+		MeasurementFromTagTemplate: "{{.Value}}-{{.Name}}",
+		Log:                        testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := testutil.MustMetric(
+		"logs",
+		map[string]string{"severity": "warn"},
+		map[string]interface{}{"message": "slow"},
+		time.Unix(0, 0),
+	)
+	actual := converter.Apply(input)
+	require.Len(t, actual, 1)
+	require.Equal(t, "warn-logs", actual[0].Name())
+
+	converter = &Converter{
+		MeasurementFromTag:         "severity",
+		MeasurementFromTagTemplate: "{{.Value",
+		Log:                        testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "compiling measurement_from_tag_template failed")
+}
+
+func TestSampling(t *testing.T) {
+	converter := &Converter{
+		SampleKey:      "request_id",
+		SampleFraction: 0.25,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	// The decision is stable for the same value, independent of the key type
+	tagged := converter.Apply(testutil.MustMetric(
+		"requests",
+		map[string]string{"request_id": "1234"},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	))
+	require.Len(t, tagged, 1)
+	decision, ok := tagged[0].GetTag("sampled")
+	require.True(t, ok)
+	for range 10 {
+		m := converter.Apply(testutil.MustMetric(
+			"requests",
+			map[string]string{},
+			map[string]interface{}{"request_id": 1234},
+			time.Unix(0, 0),
+		))
+		require.Len(t, m, 1)
+		v, ok := m[0].GetTag("sampled")
+		require.True(t, ok)
+		require.Equal(t, decision, v)
+	}
+
+	// The fraction of sampled metrics is approximately the configured one
+	const total = 10000
+	var sampled int
+	for i := range total {
+		m := converter.Apply(testutil.MustMetric(
+			"requests",
+			map[string]string{"request_id": strconv.Itoa(i)},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		))
+		if v, _ := m[0].GetTag("sampled"); v == "true" {
+			sampled++
+		}
+	}
+	require.InDelta(t, 0.25, float64(sampled)/total, 0.02)
+
+	// Metrics without the key are not tagged
+	m := converter.Apply(testutil.MustMetric(
+		"requests",
+		map[string]string{},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	))
+	require.False(t, m[0].HasTag("sampled"))
+}
+
+func TestFieldTags(t *testing.T) {
+	converter := &Converter{
+		FieldPresenceTags: map[string]string{
+			"has_error":   "error",
+			"has_latency": "latency_*",
+		},
+		FieldCountTag: "field_count",
+		DropFields:    []string{"debug"},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"value": 42, "error": "timeout", "debug": true},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"value": 23, "latency_ms": 12.5},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"host": "localhost", "has_error": "true", "has_latency": "false", "field_count": "2"},
+			map[string]interface{}{"value": 42, "error": "timeout"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"host": "localhost", "has_error": "false", "has_latency": "true", "field_count": "2"},
+			map[string]interface{}{"value": 23, "latency_ms": 12.5},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := converter.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestCollisionPolicy(t *testing.T) {
+	tests := []struct {
+		policy         string
+		expectedTags   map[string]string
+		expectedFields map[string]interface{}
+		errors         int
+	}{
+		{
+			policy:         "last",
+			expectedTags:   map[string]string{"status": "200"},
+			expectedFields: map[string]interface{}{"port": int64(8080)},
+		},
+		{
+			policy:         "first",
+			expectedTags:   map[string]string{"status": "ok"},
+			expectedFields: map[string]interface{}{"port": int64(80)},
+		},
+		{
+			policy:         "error",
+			expectedTags:   map[string]string{"status": "ok"},
+			expectedFields: map[string]interface{}{"port": int64(80)},
+			errors:         2,
+		},
+		{
+			policy:         "suffix",
+			expectedTags:   map[string]string{"status": "ok", "status_1": "200"},
+			expectedFields: map[string]interface{}{"port": int64(80), "port_1": int64(8080)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			logger := &testutil.CaptureLogger{}
+			converter := &Converter{
+				CollisionPolicy: tt.policy,
+				Tags:            &Conversion{Integer: []string{"port"}},
+				Fields:          &Conversion{Tag: []string{"status"}},
+				Log:             logger,
+			}
+			require.NoError(t, converter.Init())
+
+			input := testutil.MustMetric(
+				"http",
+				map[string]string{"port": "8080", "status": "ok"},
+				map[string]interface{}{"port": int64(80), "status": int64(200)},
+				time.Unix(0, 0),
+			)
+			expected := []telegraf.Metric{
+				testutil.MustMetric("http", tt.expectedTags, tt.expectedFields, time.Unix(0, 0)),
+			}
+
+			actual := converter.Apply(input)
+			testutil.RequireMetricsEqual(t, expected, actual)
+			require.Len(t, logger.Errors(), tt.errors)
+		})
+	}
+}
+
+func TestTimestampFromFields(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			TimestampFromFields:       []string{"date", "time"},
+			TimestampFromFieldsFormat: "2006-01-02 15:04:05",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"date": "2024-04-06", "time": "13:14:15", "value": 42},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"date": "2024-04-06", "value": 23},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"date": "2024-04-06", "time": "noon", "value": 1},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"value": 42},
+			time.Date(2024, time.April, 6, 13, 14, 15, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"date": "2024-04-06", "value": 23},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"date": "2024-04-06", "time": "noon", "value": 1},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := converter.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTimestampFromFieldsInvalid(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{TimestampFromFields: []string{"date"}},
+		Log:    testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "requires exactly two fields")
+
+	converter = &Converter{
+		Fields: &Conversion{TimestampFromFields: []string{"date", "time"}},
+		Log:    testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "timestamp_from_fields_format required")
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		value    int64
+		expected string
+	}{
+		{value: 0, expected: "0 B"},
+		{value: 512, expected: "512 B"},
+		{value: 1024, expected: "1 KiB"},
+		{value: 1536, expected: "1.5 KiB"},
+		{value: 1234567, expected: "1.2 MiB"},
+		{value: 1610612736, expected: "1.5 GiB"},
+		{value: 5 * 1024 * 1024 * 1024 * 1024, expected: "5 TiB"},
+		{value: -2048, expected: "-2 KiB"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, humanSize(tt.value), tt.value)
+	}
+}
+
+func TestHumanReadable(t *testing.T) {
+	tests := []struct {
+		name     string
+		keep     bool
+		expected telegraf.Metric
+	}{
+		{
+			name: "replace",
+			expected: testutil.MustMetric(
+				"proc",
+				map[string]string{},
+				map[string]interface{}{
+					"memory":  "1.5 GiB",
+					"runtime": "2m3s",
+					"uptime":  "1.5s",
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "keep original",
+			keep: true,
+			expected: testutil.MustMetric(
+				"proc",
+				map[string]string{},
+				map[string]interface{}{
+					"memory":        int64(1610612736),
+					"memory_human":  "1.5 GiB",
+					"runtime":       uint64(123000000000),
+					"runtime_human": "2m3s",
+					"uptime":        float64(1.5e9),
+					"uptime_human":  "1.5s",
+				},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &Converter{
+				Fields: &Conversion{
+					HumanSize:         []string{"memory"},
+					HumanDuration:     []string{"runtime", "uptime"},
+					HumanKeepOriginal: tt.keep,
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			input := testutil.MustMetric(
+				"proc",
+				map[string]string{},
+				map[string]interface{}{
+					"memory":  int64(1610612736),
+					"runtime": uint64(123000000000),
+					"uptime":  float64(1.5e9),
+				},
+				time.Unix(0, 0),
+			)
+
+			actual := converter.Apply(input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     *Conversion
+		fields   *Conversion
+		input    telegraf.Metric
+		expected telegraf.Metric
+	}{
+		{
+			name:   "base64 field to string",
+			fields: &Conversion{Base64: []string{"payload"}},
+			input: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"payload": "aGVsbG8gd29ybGQ="},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"payload": "hello world"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:   "malformed base64 field",
+			fields: &Conversion{Base64: []string{"payload"}},
+			input: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"payload": "not base64!"},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"payload": "not base64!"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:   "hex field to integer",
+			fields: &Conversion{Hex: []string{"register"}, DecodedType: "integer"},
+			input: testutil.MustMetric(
+				"modbus",
+				map[string]string{},
+				map[string]interface{}{"register": "01f4"},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"modbus",
+				map[string]string{},
+				map[string]interface{}{"register": int64(500)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:   "base64 field too long for integer",
+			fields: &Conversion{Base64: []string{"payload"}, DecodedType: "integer"},
+			input: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"payload": "aGVsbG8gd29ybGQ="},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"payload": "aGVsbG8gd29ybGQ="},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "hex tag to string",
+			tags: &Conversion{Hex: []string{"device"}},
+			input: testutil.MustMetric(
+				"msg",
+				map[string]string{"device": "73656e736f72"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"msg",
+				map[string]string{"device": "sensor"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "base64 tag to integer",
+			tags: &Conversion{Base64: []string{"id"}, DecodedType: "integer"},
+			input: testutil.MustMetric(
+				"msg",
+				map[string]string{"id": "AQI="},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"msg",
+				map[string]string{},
+				map[string]interface{}{"id": int64(258), "value": 42},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &Converter{
+				Tags:   tt.tags,
+				Fields: tt.fields,
+				Log:    testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			actual := converter.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestDecodeInvalidType(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{Base64: []string{"payload"}, DecodedType: "float"},
+		Log:    testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid decoded_type")
+}
+
+func TestValidate(t *testing.T) {
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"http",
+			map[string]string{},
+			map[string]interface{}{"status_code": "200", "method": "GET", "bytes": 42},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"http",
+			map[string]string{},
+			map[string]interface{}{"status_code": "OK", "method": "get", "bytes": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "drop",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{},
+					map[string]interface{}{"status_code": "200", "method": "GET", "bytes": 42},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric(
+					"http",
+					map[string]string{},
+					map[string]interface{}{"bytes": 42},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "flag",
+			action: "flag",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"http",
+					map[string]string{},
+					map[string]interface{}{"status_code": "200", "method": "GET", "bytes": 42},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric(
+					"http",
+					map[string]string{"invalid_fields": "method,status_code"},
+					map[string]interface{}{"status_code": "OK", "method": "get", "bytes": 42},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &Converter{
+				Validate: map[string]string{
+					"status_*": `^[0-9]{3}$`,
+					"method":   `^[A-Z]+$`,
+					"bytes":    `^[0-9]+$`,
+				},
+				ValidateAction: tt.action,
+				Log:            testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			metrics := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				metrics = append(metrics, m.Copy())
+			}
+			actual := converter.Apply(metrics...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	converter := &Converter{
+		Validate: map[string]string{"status": "(unclosed"},
+		Log:      testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), `compiling validate expression for "status" failed`)
+
+	converter = &Converter{
+		Validate:       map[string]string{"status": "^[0-9]+$"},
+		ValidateAction: "ignore",
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid validate_action")
+}
+
+func TestMeasurementTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     *Conversion
+		fields   *Conversion
+		input    telegraf.Metric
+		expected telegraf.Metric
+	}{
+		{
+			name: "tag with static prefix",
+			tags: &Conversion{
+				Measurement:         []string{"service"},
+				MeasurementTemplate: "app_{{.service}}",
+			},
+			input: testutil.MustMetric(
+				"logs",
+				map[string]string{"service": "billing", "host": "a"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"app_billing",
+				map[string]string{"host": "a"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "field combined with tag",
+			fields: &Conversion{
+				Measurement:         []string{"kind"},
+				MeasurementTemplate: "{{.env}}_{{.kind}}",
+			},
+			input: testutil.MustMetric(
+				"logs",
+				map[string]string{"env": "prod"},
+				map[string]interface{}{"kind": "audit", "value": 42},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"prod_audit",
+				map[string]string{"env": "prod"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "missing template key",
+			tags: &Conversion{
+				Measurement:         []string{"service"},
+				MeasurementTemplate: "{{.env}}_{{.service}}",
+			},
+			input: testutil.MustMetric(
+				"logs",
+				map[string]string{"service": "billing"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"logs",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "no template",
+			tags: &Conversion{
+				Measurement: []string{"service"},
+			},
+			input: testutil.MustMetric(
+				"logs",
+				map[string]string{"service": "billing"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"billing",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &Converter{
+				Tags:   tt.tags,
+				Fields: tt.fields,
+				Log:    testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			actual := converter.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestMeasurementTemplateInvalid(t *testing.T) {
+	converter := &Converter{
+		Tags: &Conversion{
+			Measurement:         []string{"service"},
+			MeasurementTemplate: "{{.service",
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "compiling measurement_template failed")
+}
+
+func TestScaleShift(t *testing.T) {
+	converter := &Converter{
+		Tags: &Conversion{
+			Integer: []string{"port"},
+			Shift:   map[string]float64{"integer": 1000},
+		},
+		Fields: &Conversion{
+			Float:    []string{"bytes"},
+			Integer:  []string{"ratio"},
+			Unsigned: []string{"temperature"},
+			Scale:    map[string]float64{"float": 0.001, "integer": 100, "unsigned": 1.8},
+			Shift:    map[string]float64{"unsigned": 32},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, converter.Init())
+
+	input := testutil.MustMetric(
+		"system",
+		map[string]string{"port": "80"},
+		map[string]interface{}{
+			"bytes":       "1536",
+			"ratio":       "12",
+			"temperature": int64(21),
+		},
+		time.Unix(0, 0),
+	)
+	expected := testutil.MustMetric(
+		"system",
+		map[string]string{},
+		map[string]interface{}{
+			"port":        int64(1080),
+			"bytes":       float64(1.536),
+			"ratio":       int64(1200),
+			"temperature": uint64(70),
+		},
+		time.Unix(0, 0),
+	)
+
+	actual := converter.Apply(input)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, actual)
+}
+
+func TestScaleInvalidCategory(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			String: []string{"a"},
+			Scale:  map[string]float64{"string": 2},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), `invalid scale category "string"`)
+}
+
+func TestCollisionPolicyInvalid(t *testing.T) {
+	converter := &Converter{
+		CollisionPolicy: "random",
+		Fields:          &Conversion{Tag: []string{"status"}},
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid collision_policy")
+}
+
+func TestSamplingInvalidFraction(t *testing.T) {
+	converter := &Converter{
+		SampleKey:      "request_id",
+		SampleFraction: 1.5,
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid sample_fraction")
+}
+
+func TestSamplingMissingFraction(t *testing.T) {
+	converter := &Converter{
+		SampleKey: "request_id",
+		Log:       testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "sample_fraction is required")
+}
+
+func TestEmptyConfigInitError(t *testing.T) {
+	converter := &Converter{
+		Log: testutil.Logger{},
+	}
+	require.Error(t, converter.Init())
+}
+
+func TestInvalidFloatNonFinite(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			Float:          []string{"a"},
+			FloatNonFinite: "foo",
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), "invalid float_non_finite setting")
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := []telegraf.Metric{
+		metric.New("foo", map[string]string{}, map[string]interface{}{"value": 42, "topic": "telegraf"}, time.Unix(0, 0)),
+		metric.New("bar", map[string]string{}, map[string]interface{}{"value": 42, "topic": "telegraf"}, time.Unix(0, 0)),
+		metric.New("baz", map[string]string{}, map[string]interface{}{"value": 42, "topic": "telegraf"}, time.Unix(0, 0)),
+	}
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("telegraf", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		metric.New("telegraf", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		metric.New("telegraf", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+	}
+
+	plugin := &Converter{
+		Fields: &Conversion{
+			Measurement: []string{"topic"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Process expected metrics and compare with resulting metrics
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
+}
+
+func TestFloatRounding(t *testing.T) {
+	tests := []struct {
+		rounding string
+		expected int64
+		unsigned uint64
+	}{
+		{rounding: "", expected: 3, unsigned: 3},
+		{rounding: "round", expected: 3, unsigned: 3},
+		{rounding: "floor", expected: 2, unsigned: 2},
+		{rounding: "ceil", expected: 3, unsigned: 3},
+		{rounding: "trunc", expected: 2, unsigned: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rounding, func(t *testing.T) {
+			converter := &Converter{
+				Fields: &Conversion{
+					Integer:  []string{"a", "b"},
+					Unsigned: []string{"c", "d"},
+				},
+				FloatRounding: tt.rounding,
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			input := testutil.MustMetric(
+				"test",
+				map[string]string{},
+				map[string]interface{}{
+					"a": 2.7,
+					"b": "2.7",
+					"c": 2.7,
+					"d": "2.7",
+				},
+				time.Unix(0, 0),
+			)
+			expected := testutil.MustMetric(
+				"test",
+				map[string]string{},
+				map[string]interface{}{
+					"a": tt.expected,
+					"b": tt.expected,
+					"c": tt.unsigned,
+					"d": tt.unsigned,
+				},
+				time.Unix(0, 0),
+			)
+
+			actual := converter.Apply(input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, actual)
+		})
+	}
+}
+
+func TestFloatRoundingInvalid(t *testing.T) {
+	converter := &Converter{
+		Fields: &Conversion{
+			Integer: []string{"a"},
+		},
+		FloatRounding: "nearest",
+		Log:           testutil.Logger{},
+	}
+	require.ErrorContains(t, converter.Init(), `invalid float_rounding "nearest"`)
+}
+
+func TestTimestampUnixFormats(t *testing.T) {
+	epoch := time.Unix(1677610769, 123456789)
+	tests := []struct {
+		format   string
+		value    int64
+		expected time.Time
+	}{
+		{format: "unix", value: 1677610769, expected: time.Unix(1677610769, 0)},
+		{format: "unix_ms", value: 1677610769123, expected: time.Unix(1677610769, 123000000)},
+		{format: "unix_us", value: 1677610769123456, expected: time.Unix(1677610769, 123456000)},
+		{format: "unix_ns", value: epoch.UnixNano(), expected: epoch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			converter := &Converter{
+				Tags: &Conversion{
+					Timestamp:       []string{"time"},
+					TimestampFormat: tt.format,
+				},
+				Fields: &Conversion{
+					Timestamp:       []string{"time"},
+					TimestampFormat: tt.format,
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, converter.Init())
+
+			fromField := testutil.MustMetric(
+				"test",
+				map[string]string{},
+				map[string]interface{}{
+					"a":    int64(42),
+					"time": tt.value,
+				},
+				time.Unix(0, 0),
+			)
+			fromTag := testutil.MustMetric(
+				"test",
+				map[string]string{"time": strconv.FormatInt(tt.value, 10)},
+				map[string]interface{}{"a": int64(42)},
+				time.Unix(0, 0),
+			)
+			expected := testutil.MustMetric(
+				"test",
+				map[string]string{},
+				map[string]interface{}{"a": int64(42)},
+				tt.expected,
+			)
+
+			actual := converter.Apply(fromField, fromTag)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{expected, expected}, actual)
+		})
+	}
 }
@@ -3,9 +3,12 @@ package influx
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 
+	"github.com/klauspost/compress/gzip"
+
 	"github.com/influxdata/telegraf"
 )
 
@@ -27,6 +30,22 @@ func NewReader(metrics []telegraf.Metric, serializer *Serializer) io.Reader {
 	}
 }
 
+// EstimateSize returns the number of bytes produced when reading the given
+// metrics using a reader with this serializer. This allows to pre-allocate
+// output buffers. Metrics that cannot be serialized do not contribute to the
+// size as they are skipped by the reader.
+func (s *Serializer) EstimateSize(metrics []telegraf.Metric) int {
+	var size int
+	for _, m := range metrics {
+		start := s.bytesWritten
+		if err := s.Write(io.Discard, m); err != nil {
+			continue
+		}
+		size += s.bytesWritten - start
+	}
+	return size
+}
+
 // SetMetrics changes the metrics to be read.
 func (r *reader) SetMetrics(metrics []telegraf.Metric) {
 	r.metrics = metrics
@@ -68,3 +87,57 @@ func (r *reader) Read(p []byte) (int, error) {
 
 	return r.buf.Read(p)
 }
+
+// gzipReader is an io.Reader for gzip compressed line protocol.
+type gzipReader struct {
+	reader io.Reader
+	writer *gzip.Writer
+	chunk  []byte
+	buf    *bytes.Buffer
+	done   bool
+}
+
+// NewGzipReader creates a new reader over the given metrics producing gzip
+// compressed line protocol with the given compression level. The metrics are
+// serialized and compressed on demand, so the uncompressed output is never
+// held in memory as a whole.
+func NewGzipReader(metrics []telegraf.Metric, serializer *Serializer, level int) (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	writer, err := gzip.NewWriterLevel(buf, level)
+	if err != nil {
+		return nil, fmt.Errorf("creating gzip writer failed: %w", err)
+	}
+	return &gzipReader{
+		reader: NewReader(metrics, serializer),
+		writer: writer,
+		chunk:  make([]byte, 32*1024),
+		buf:    buf,
+	}, nil
+}
+
+// Read reads up to len(p) bytes of compressed data into p. Metrics are
+// serialized and compressed until compressed data is available or all
+// metrics are consumed. When all data is emitted the err is io.EOF.
+func (r *gzipReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 && !r.done {
+		n, err := r.reader.Read(r.chunk)
+		if n > 0 {
+			if _, werr := r.writer.Write(r.chunk[:n]); werr != nil {
+				return 0, werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			if err := r.writer.Close(); err != nil {
+				return 0, err
+			}
+			r.done = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	if r.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.buf.Read(p)
+}
@@ -2,10 +2,13 @@ package influx
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/gzip"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -154,6 +157,126 @@ func TestReader(t *testing.T) {
 	}
 }
 
+func TestEstimateSize(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 42.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu load,total",
+			map[string]string{
+				"host name": "local,host",
+				"cpu=x":     "cpu\\0",
+			},
+			map[string]interface{}{
+				"usage idle": 42.0,
+				"note":       "say \"hello\"\n\\",
+				"count":      int64(-23),
+				"ok":         true,
+				"unsigned":   uint64(42),
+			},
+			time.Unix(1519194109, 42),
+		),
+		metric.New(
+			"no_fields",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"unicode",
+			map[string]string{"city": "Zürich"},
+			map[string]interface{}{
+				"temperature": 21.5,
+				"text":        "日本語",
+			},
+			time.Unix(1519194109, 0),
+		),
+	}
+
+	for _, maxLineBytes := range []int{0, 40} {
+		t.Run("max line bytes "+strconv.Itoa(maxLineBytes), func(t *testing.T) {
+			serializer := &Serializer{
+				MaxLineBytes: maxLineBytes,
+				SortFields:   true,
+				UintSupport:  true,
+			}
+			require.NoError(t, serializer.Init())
+			estimated := serializer.EstimateSize(metrics)
+
+			data, err := io.ReadAll(NewReader(metrics, serializer))
+			require.NoError(t, err)
+			require.NotEmpty(t, data)
+			require.Len(t, data, estimated)
+		})
+	}
+}
+
+func TestGzipReader(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 1000)
+	for i := range 1000 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "server" + strconv.Itoa(i%10)},
+			map[string]interface{}{"value": float64(i), "count": i},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	serializer := &Serializer{SortFields: true}
+	require.NoError(t, serializer.Init())
+	expected, err := io.ReadAll(NewReader(metrics, serializer))
+	require.NoError(t, err)
+
+	for _, level := range []int{gzip.NoCompression, gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression} {
+		t.Run(strconv.Itoa(level), func(t *testing.T) {
+			r, err := NewGzipReader(metrics, serializer, level)
+			require.NoError(t, err)
+
+			// Read with a small buffer to exercise partial reads
+			var compressed bytes.Buffer
+			buf := make([]byte, 100)
+			for {
+				n, err := r.Read(buf)
+				compressed.Write(buf[:n])
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+			}
+
+			decompressor, err := gzip.NewReader(&compressed)
+			require.NoError(t, err)
+			actual, err := io.ReadAll(decompressor)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestGzipReaderNoMetrics(t *testing.T) {
+	r, err := NewGzipReader(nil, &Serializer{}, gzip.DefaultCompression)
+	require.NoError(t, err)
+
+	compressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	decompressor, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	actual, err := io.ReadAll(decompressor)
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func TestGzipReaderInvalidLevel(t *testing.T) {
+	_, err := NewGzipReader(nil, &Serializer{}, 42)
+	require.ErrorContains(t, err, "creating gzip writer failed")
+}
+
 func TestZeroLengthBufferNoError(t *testing.T) {
 	m := metric.New(
 		"cpu",